@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/angelini/sblocks/internal/log"
+	"github.com/angelini/sblocks/pkg/spec"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func NewCmdApply() *cobra.Command {
+	var (
+		specPath string
+		yes      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Create, update, resize or delete blocks to match a spec file",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			desired, err := spec.Load(specPath)
+			if err != nil {
+				return err
+			}
+
+			client, err := newCloudRunClient(ctx)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			plan, err := spec.NewPlan(ctx, client, desired)
+			if err != nil {
+				return err
+			}
+
+			printPlan(plan)
+			if len(plan.Actions) == 0 {
+				return nil
+			}
+
+			if !yes && !confirm(fmt.Sprintf("Apply %d actions?", len(plan.Actions))) {
+				return fmt.Errorf("apply cancelled")
+			}
+
+			err = plan.Apply(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			log.Info(ctx, "applied spec", zap.String("file", specPath), zap.Int("actions", len(plan.Actions)))
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&specPath, "file", "f", "", "Path to a YAML or JSON spec file")
+	cmd.PersistentFlags().BoolVarP(&yes, "yes", "y", false, "Skip the confirmation prompt")
+
+	cmd.MarkPersistentFlagRequired("file")
+
+	return cmd
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/angelini/sblocks/pkg/cloudrun"
+)
+
+type cloudRunClient interface {
+	cloudrun.Backend
+	Close() error
+}
+
+// newCloudRunClient reads GCP_REGION as a single region or as a comma separated list of
+// regions with optional weights, e.g. "us-central1=2,europe-west1=1".
+func newCloudRunClient(ctx context.Context) (cloudRunClient, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	store := cloudrun.NewFileOperationStore(filepath.Join(configDir, "sblocks", "operations.json"))
+
+	placement, err := cloudrun.ParsePlacement(os.Getenv("GCP_REGION"))
+	if err != nil {
+		return nil, err
+	}
+
+	regions := placement.Regions()
+	if len(regions) == 1 {
+		return cloudrun.NewClient(ctx, os.Getenv("GCP_PROJECT"), regions[0], cloudrun.WithOperationStore(store))
+	}
+
+	backends := make(map[string]cloudrun.Backend, len(regions))
+	for _, region := range regions {
+		client, err := cloudrun.NewClient(ctx, os.Getenv("GCP_PROJECT"), region, cloudrun.WithOperationStore(store))
+		if err != nil {
+			for _, backend := range backends {
+				backend.(*cloudrun.Client).Close()
+			}
+			return nil, err
+		}
+		backends[region] = client
+	}
+
+	return cloudrun.NewMultiRegionBackend(backends, placement)
+}
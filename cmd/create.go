@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"time"
@@ -13,8 +14,15 @@ import (
 
 func NewCmdCreate() *cobra.Command {
 	var (
-		environment string
-		size        int
+		environment    string
+		size           int
+		serviceAccount string
+		ingress        string
+		vpcConnector   string
+		vpcNetwork     string
+		vpcSubnetwork  string
+		vpcEgress      string
+		keepPartial    bool
 	)
 
 	cmd := &cobra.Command{
@@ -23,14 +31,24 @@ func NewCmdCreate() *cobra.Command {
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			ctx := cmd.Context()
 
-			client, err := cloudrun.NewClient(ctx, os.Getenv("GCP_PROJECT"), os.Getenv("GCP_REGION"))
+			client, err := newCloudRunClient(ctx)
 			if err != nil {
 				return err
 			}
 			defer client.Close()
 
 			labels := map[string]string{
-				"sb_environment": environment,
+				cloudrun.EnvironmentLabel: environment,
+			}
+
+			var vpcAccess *cloudrun.VPCAccess
+			if vpcConnector != "" || vpcNetwork != "" || vpcSubnetwork != "" {
+				vpcAccess = &cloudrun.VPCAccess{
+					Connector:  vpcConnector,
+					Network:    vpcNetwork,
+					Subnetwork: vpcSubnetwork,
+					Egress:     cloudrun.VPCEgress(vpcEgress),
+				}
 			}
 
 			block, err := cloudrun.CreateServiceBlock(ctx, client, true, size, labels, &cloudrun.Revision{
@@ -40,9 +58,17 @@ func NewCmdCreate() *cobra.Command {
 				MaxConcurrency: 50,
 				Timeout:        time.Minute,
 				Containers: map[string]cloudrun.Container{
-					"deno": {Name: "deno", Image: os.Getenv("DENO_IMAGE")},
+					"deno": denoContainer(),
 				},
-			})
+				ServiceAccount: serviceAccount,
+				VPCAccess:      vpcAccess,
+				Ingress:        cloudrun.Ingress(ingress),
+			}, keepPartial)
+
+			var partialErr *cloudrun.PartialBlockError
+			if errors.As(err, &partialErr) {
+				fmt.Printf("resume with: sblocks scale -e %s -b %s -s %d\n", environment, partialErr.Block.Name(), partialErr.Size)
+			}
 			if err != nil {
 				return err
 			}
@@ -56,9 +82,12 @@ func NewCmdCreate() *cobra.Command {
 				MaxConcurrency: 50,
 				Timeout:        time.Minute,
 				Containers: map[string]cloudrun.Container{
-					"deno": {Name: "deno", Image: os.Getenv("DENO_IMAGE")},
+					"deno": denoContainer(),
 				},
-			})
+				ServiceAccount: serviceAccount,
+				VPCAccess:      vpcAccess,
+				Ingress:        cloudrun.Ingress(ingress),
+			}, "")
 
 			fmt.Println()
 			for _, line := range block.Display() {
@@ -71,8 +100,31 @@ func NewCmdCreate() *cobra.Command {
 
 	cmd.PersistentFlags().StringVarP(&environment, "environment", "e", "", "Name of the environment that the block will be added to")
 	cmd.PersistentFlags().IntVarP(&size, "size", "s", 10, "Size of the service block")
+	cmd.PersistentFlags().StringVar(&serviceAccount, "service-account", "", "Service account used by every revision in the block")
+	cmd.PersistentFlags().StringVar(&ingress, "ingress", string(cloudrun.IngressAll), "Ingress mode (all, internal or internal-and-cloud-load-balancing)")
+	cmd.PersistentFlags().StringVar(&vpcConnector, "vpc-connector", "", "Serverless VPC Access connector used for egress")
+	cmd.PersistentFlags().StringVar(&vpcNetwork, "vpc-network", "", "VPC network used for direct VPC egress")
+	cmd.PersistentFlags().StringVar(&vpcSubnetwork, "vpc-subnetwork", "", "VPC subnetwork used for direct VPC egress")
+	cmd.PersistentFlags().StringVar(&vpcEgress, "vpc-egress", string(cloudrun.EgressPrivateRangesOnly), "VPC egress setting (all-traffic or private-ranges-only)")
+
+	cmd.PersistentFlags().BoolVar(&keepPartial, "keep-partial", false, "Keep the services created before a failure instead of deleting them")
 
 	cmd.MarkPersistentFlagRequired("environment")
 
 	return cmd
 }
+
+func denoContainer() cloudrun.Container {
+	return cloudrun.Container{
+		Name:  "deno",
+		Image: os.Getenv("DENO_IMAGE"),
+		Ports: []cloudrun.Port{{Name: "http1", Number: 8080}},
+		StartupProbe: &cloudrun.Probe{
+			Type:             cloudrun.TCPProbe,
+			Port:             8080,
+			Timeout:          time.Second,
+			Period:           2 * time.Second,
+			FailureThreshold: 30,
+		},
+	}
+}
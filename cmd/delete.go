@@ -1,35 +1,92 @@
 package cmd
 
 import (
+	"bufio"
+	"fmt"
 	"os"
+	"strings"
 
 	"github.com/angelini/sblocks/internal/log"
 	"github.com/angelini/sblocks/pkg/cloudrun"
 	"github.com/spf13/cobra"
+	"go.uber.org/zap"
 )
 
 func NewCmdDelete() *cobra.Command {
+	var (
+		environment string
+		blockName   string
+		dryRun      bool
+		yes         bool
+	)
+
 	cmd := &cobra.Command{
 		Use:   "delete",
-		Short: "Delete all service blocks",
+		Short: "Delete the service blocks of an environment",
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			ctx := cmd.Context()
 
-			client, err := cloudrun.NewClient(ctx, os.Getenv("GCP_PROJECT"), os.Getenv("GCP_REGION"))
+			client, err := newCloudRunClient(ctx)
 			if err != nil {
 				return err
 			}
 			defer client.Close()
 
-			err = client.DeleteAll(ctx)
+			serviceNames, err := cloudrun.FindDeletable(ctx, client, cloudrun.DeleteScope{
+				Environment: environment,
+				Block:       blockName,
+			})
+			if err != nil {
+				return err
+			}
+
+			if len(serviceNames) == 0 {
+				fmt.Println("no services to delete")
+				return nil
+			}
+
+			action := "delete"
+			if dryRun {
+				action = "would delete"
+			}
+			for _, serviceName := range serviceNames {
+				fmt.Printf("%s %s\n", action, serviceName)
+			}
+
+			if dryRun {
+				return nil
+			}
+
+			if !yes && !confirm(fmt.Sprintf("Delete %d services?", len(serviceNames))) {
+				return fmt.Errorf("delete cancelled")
+			}
+
+			err = cloudrun.DeleteServices(ctx, client, serviceNames)
 			if err != nil {
 				return err
 			}
 
-			log.Info(ctx, "deleted all service blocks")
+			log.Info(ctx, "deleted services", zap.Strings("services", serviceNames))
 			return nil
 		},
 	}
 
+	cmd.PersistentFlags().StringVarP(&environment, "environment", "e", "", "Only delete services in this environment")
+	cmd.PersistentFlags().StringVarP(&blockName, "block", "b", "", "Only delete the services of this block")
+	cmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "List the services that would be deleted without deleting them")
+	cmd.PersistentFlags().BoolVarP(&yes, "yes", "y", false, "Skip the confirmation prompt")
+
 	return cmd
 }
+
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/angelini/sblocks/internal/maps"
+	"github.com/angelini/sblocks/pkg/cloudrun"
+	"github.com/angelini/sblocks/pkg/spec"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdDrift() *cobra.Command {
+	var (
+		environment string
+		blockName   string
+		public      bool
+		ingress     string
+		specPath    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "drift",
+		Short: "Report services that differ from their block's deployed spec",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			client, err := newCloudRunClient(ctx)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			blocks, err := cloudrun.LoadServiceBlocks(ctx, client, environment)
+			if err != nil {
+				return err
+			}
+
+			if blockName != "" {
+				block, found := blocks[blockName]
+				if !found {
+					return fmt.Errorf("block %s not found in environment %s", blockName, environment)
+				}
+				blocks = map[string]*cloudrun.ServiceBlock{blockName: block}
+			}
+
+			var recorded *spec.Spec
+			if specPath != "" {
+				recorded, err = spec.Load(specPath)
+				if err != nil {
+					return err
+				}
+			}
+
+			drifted := 0
+			for _, block := range maps.SortedValues(blocks) {
+				var desired *cloudrun.Revision
+				var expectPublic bool
+
+				if recorded != nil {
+					blockSpec := recorded.FindBlock(environment, block.Name())
+					if blockSpec == nil {
+						fmt.Printf("%s: not found in %s\n", block.Name(), specPath)
+						drifted++
+						continue
+					}
+
+					desired = blockSpec.Revision.Revision()
+					expectPublic = blockSpec.Public
+				} else {
+					// without a spec, the majority of the services stands in for the intent
+					desired, err = block.DesiredRevision()
+					if err != nil {
+						return err
+					}
+
+					expectPublic, err = block.ExpectedPublic(ctx, client)
+					if err != nil {
+						return err
+					}
+				}
+
+				if ingress != "" {
+					desired.Ingress = cloudrun.Ingress(ingress)
+				}
+				if cmd.Flags().Changed("public") {
+					expectPublic = public
+				}
+
+				drifts, err := block.DetectDrift(ctx, client, desired, expectPublic)
+				if err != nil {
+					return err
+				}
+
+				for _, drift := range drifts {
+					fmt.Printf("%s/%s:\n", block.Name(), drift.Service)
+					for _, field := range drift.Fields {
+						fmt.Printf("  %s\n", field.String())
+					}
+				}
+				drifted += len(drifts)
+			}
+
+			if drifted > 0 {
+				return fmt.Errorf("drift detected in %d services", drifted)
+			}
+
+			fmt.Println("no drift detected")
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&environment, "environment", "e", "", "Name of the environment to check")
+	cmd.PersistentFlags().StringVarP(&blockName, "block", "b", "", "Only check this service block")
+	cmd.PersistentFlags().StringVarP(&specPath, "file", "f", "", "Spec to compare against, defaults to the state shared by most services")
+	cmd.PersistentFlags().BoolVar(&public, "public", false, "Whether services should allow unauthenticated access, defaults to the spec or the majority of services")
+	cmd.PersistentFlags().StringVar(&ingress, "ingress", "", "Expected ingress, defaults to the spec or the majority of services")
+
+	cmd.MarkPersistentFlagRequired("environment")
+
+	return cmd
+}
@@ -6,13 +6,10 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"github.com/angelini/sblocks/internal/log"
-	"github.com/angelini/sblocks/pkg/cloudrun"
 	"github.com/angelini/sblocks/pkg/executor"
 	"github.com/spf13/cobra"
-	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.uber.org/zap"
 )
 
@@ -27,16 +24,13 @@ func NewCmdExecutor() *cobra.Command {
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			ctx := cmd.Context()
 
-			etcd, err := clientv3.New(clientv3.Config{
-				Endpoints:   []string{"localhost:2379", "localhost:22379", "localhost:32379"},
-				DialTimeout: 5 * time.Second,
-			})
+			etcd, err := newEtcdClient()
 			if err != nil {
 				return err
 			}
 			defer etcd.Close()
 
-			client, err := cloudrun.NewClient(ctx, os.Getenv("GCP_PROJECT"), os.Getenv("GCP_REGION"))
+			client, err := newCloudRunClient(ctx)
 			if err != nil {
 				return err
 			}
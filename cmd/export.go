@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/angelini/sblocks/pkg/spec"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdExport() *cobra.Command {
+	var (
+		environment string
+		output      string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write the blocks of an environment as a spec file",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			client, err := newCloudRunClient(ctx)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			exported, err := spec.Export(ctx, client, environment)
+			if err != nil {
+				return err
+			}
+
+			content, err := (&spec.Spec{Environments: []spec.EnvironmentSpec{*exported}}).Marshal()
+			if err != nil {
+				return err
+			}
+
+			if output == "" {
+				fmt.Print(string(content))
+				return nil
+			}
+
+			return os.WriteFile(output, content, 0644)
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&environment, "environment", "e", "", "Name of the environment to export")
+	cmd.PersistentFlags().StringVarP(&output, "output", "o", "", "Path of the spec file, defaults to stdout")
+
+	cmd.MarkPersistentFlagRequired("environment")
+
+	return cmd
+}
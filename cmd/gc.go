@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/angelini/sblocks/internal/maps"
+	"github.com/angelini/sblocks/pkg/cloudrun"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdGc() *cobra.Command {
+	var (
+		environment string
+		blockName   string
+		keep        int
+		dryRun      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Delete old revisions that no longer receive traffic",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			client, err := newCloudRunClient(ctx)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			blocks, err := cloudrun.LoadServiceBlocks(ctx, client, environment)
+			if err != nil {
+				return err
+			}
+
+			if blockName != "" {
+				block, found := blocks[blockName]
+				if !found {
+					return fmt.Errorf("block %s not found in environment %s", blockName, environment)
+				}
+				blocks = map[string]*cloudrun.ServiceBlock{blockName: block}
+			}
+
+			action := "deleted"
+			if dryRun {
+				action = "would delete"
+			}
+
+			for _, name := range maps.SortedKeys(blocks) {
+				pruned, err := blocks[name].PruneRevisions(ctx, client, keep, dryRun)
+
+				for _, serviceName := range maps.SortedKeys(pruned) {
+					for _, revision := range pruned[serviceName] {
+						fmt.Printf("%s %s/%s\n", action, serviceName, revision)
+					}
+				}
+
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&environment, "environment", "e", "", "Name of the environment to clean up")
+	cmd.PersistentFlags().StringVarP(&blockName, "block", "b", "", "Limit cleanup to a single service block")
+	cmd.PersistentFlags().IntVar(&keep, "keep", 3, "Number of recent revisions to keep per service")
+	cmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "List the revisions that would be deleted without deleting them")
+
+	cmd.MarkPersistentFlagRequired("environment")
+
+	return cmd
+}
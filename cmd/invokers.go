@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/angelini/sblocks/internal/maps"
+	"github.com/angelini/sblocks/pkg/cloudrun"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdInvokers() *cobra.Command {
+	var (
+		environment string
+		blockName   string
+		add         []string
+		remove      []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "invokers",
+		Short: "Show or edit the members allowed to invoke a block",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			client, err := newCloudRunClient(ctx)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			blocks, err := cloudrun.LoadServiceBlocks(ctx, client, environment)
+			if err != nil {
+				return err
+			}
+
+			block, found := blocks[blockName]
+			if !found {
+				return fmt.Errorf("block %s not found in environment %s", blockName, environment)
+			}
+
+			if len(add) > 0 || len(remove) > 0 {
+				err = block.UpdateInvokers(ctx, client, add, remove)
+				if err != nil {
+					return err
+				}
+			}
+
+			invokers, err := block.Invokers(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			for _, serviceName := range maps.SortedKeys(invokers) {
+				members := invokers[serviceName]
+				if len(members) == 0 {
+					fmt.Printf("%s: <none>\n", serviceName)
+					continue
+				}
+				fmt.Printf("%s: %s\n", serviceName, strings.Join(members, ", "))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&environment, "environment", "e", "", "Name of the environment that contains the block")
+	cmd.PersistentFlags().StringVarP(&blockName, "block", "b", "", "Name of the service block")
+	cmd.PersistentFlags().StringSliceVar(&add, "add", nil, "Members to grant the invoker role, e.g. serviceAccount:name@project.iam.gserviceaccount.com")
+	cmd.PersistentFlags().StringSliceVar(&remove, "remove", nil, "Members to remove from the invoker role")
+
+	cmd.MarkPersistentFlagRequired("environment")
+	cmd.MarkPersistentFlagRequired("block")
+
+	return cmd
+}
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/angelini/sblocks/internal/maps"
 	"github.com/angelini/sblocks/pkg/cloudrun"
@@ -20,7 +19,7 @@ func NewCmdList() *cobra.Command {
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			ctx := cmd.Context()
 
-			client, err := cloudrun.NewClient(ctx, os.Getenv("GCP_PROJECT"), os.Getenv("GCP_REGION"))
+			client, err := newCloudRunClient(ctx)
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/angelini/sblocks/internal/maps"
+	"github.com/angelini/sblocks/pkg/cloudrun"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdMigrate() *cobra.Command {
+	var (
+		environment string
+		dryRun      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Label services created before blocks carried identity labels",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			client, err := newCloudRunClient(ctx)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			migrated, err := cloudrun.MigrateLabels(ctx, client, environment, dryRun)
+
+			action := "labelled"
+			if dryRun {
+				action = "would label"
+			}
+			for _, serviceName := range maps.SortedKeys(migrated) {
+				labels := migrated[serviceName]
+				fmt.Printf("%s %s: %s=%s, %s=%s\n", action, serviceName, cloudrun.BlockLabel, labels[cloudrun.BlockLabel], cloudrun.IndexLabel, labels[cloudrun.IndexLabel])
+			}
+
+			return err
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&environment, "environment", "e", "", "Name of the environment to migrate")
+	cmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "List the services that would be labelled without updating them")
+
+	cmd.MarkPersistentFlagRequired("environment")
+
+	return cmd
+}
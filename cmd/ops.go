@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func NewCmdOps() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ops",
+		Short: "List pending Cloud Run operations started by sblocks",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			client, err := newCloudRunClient(ctx)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			operations, err := client.PendingOperations(ctx)
+			if err != nil {
+				return err
+			}
+
+			if len(operations) == 0 {
+				fmt.Println("no pending operations")
+				return nil
+			}
+
+			for _, operation := range operations {
+				fmt.Println(operation.String())
+				fmt.Printf("  %s\n", operation.Name)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
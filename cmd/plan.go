@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/angelini/sblocks/pkg/spec"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdPlan() *cobra.Command {
+	var (
+		specPath string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Show the changes needed to match a spec file",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			desired, err := spec.Load(specPath)
+			if err != nil {
+				return err
+			}
+
+			client, err := newCloudRunClient(ctx)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			plan, err := spec.NewPlan(ctx, client, desired)
+			if err != nil {
+				return err
+			}
+
+			printPlan(plan)
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&specPath, "file", "f", "", "Path to a YAML or JSON spec file")
+
+	cmd.MarkPersistentFlagRequired("file")
+
+	return cmd
+}
+
+func printPlan(plan *spec.Plan) {
+	if len(plan.Actions) == 0 {
+		fmt.Println("no changes")
+		return
+	}
+
+	for _, action := range plan.Actions {
+		fmt.Println(action.String())
+	}
+}
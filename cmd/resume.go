@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"github.com/angelini/sblocks/internal/log"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+func NewCmdResume() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resume",
+		Short: "Wait for pending Cloud Run operations started by sblocks to complete",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			client, err := newCloudRunClient(ctx)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			operations, err := client.PendingOperations(ctx)
+			if err != nil {
+				return err
+			}
+
+			group, groupCtx := errgroup.WithContext(ctx)
+			if limit := client.Concurrency(); limit > 0 {
+				group.SetLimit(limit)
+			}
+
+			for _, operation := range operations {
+				operation := operation
+				group.Go(func() error {
+					return client.WaitOperation(groupCtx, operation)
+				})
+			}
+
+			err = group.Wait()
+			if err != nil {
+				return err
+			}
+
+			log.Info(ctx, "resumed pending operations", zap.Int("count", len(operations)))
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/angelini/sblocks/internal/maps"
+	"github.com/angelini/sblocks/pkg/cloudrun"
+	"github.com/angelini/sblocks/pkg/rollout"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdRollback() *cobra.Command {
+	var (
+		environment  string
+		blockName    string
+		revisionName string
+		undo         bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Send all traffic of a service block back to an existing revision",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			etcd, err := newEtcdClient()
+			if err != nil {
+				return err
+			}
+			defer etcd.Close()
+
+			client, err := newCloudRunClient(ctx)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			blocks, err := cloudrun.LoadServiceBlocks(ctx, client, environment)
+			if err != nil {
+				return err
+			}
+
+			block, found := blocks[blockName]
+			if !found {
+				return fmt.Errorf("block %s not found in environment %s", blockName, environment)
+			}
+
+			controller := rollout.NewController(client, rollout.NewEtcdStore(etcd), rollout.DefaultConfig())
+
+			var rollback *rollout.Rollback
+			if undo {
+				rollback, err = controller.UndoRollback(ctx, environment, block)
+			} else {
+				rollback, err = controller.Rollback(ctx, environment, block, revisionName)
+			}
+
+			if rollback != nil {
+				for _, serviceName := range maps.SortedKeys(rollback.Failed) {
+					fmt.Printf("failed %s: %s\n", serviceName, rollback.Failed[serviceName])
+				}
+			}
+			if err != nil {
+				return err
+			}
+
+			fmt.Println()
+			for _, line := range block.Display() {
+				fmt.Println(line)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&environment, "environment", "e", "", "Name of the environment that contains the block")
+	cmd.PersistentFlags().StringVarP(&blockName, "block", "b", "", "Name of the service block")
+	cmd.PersistentFlags().StringVarP(&revisionName, "revision", "r", rollout.PreviousRevision, "Name of the revision to roll back to, or \"previous\"")
+	cmd.PersistentFlags().BoolVar(&undo, "undo", false, "Restore the traffic recorded by the last rollback")
+
+	cmd.MarkPersistentFlagRequired("environment")
+	cmd.MarkPersistentFlagRequired("block")
+
+	return cmd
+}
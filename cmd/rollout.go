@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/angelini/sblocks/pkg/cloudrun"
+	"github.com/angelini/sblocks/pkg/rollout"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdRollout() *cobra.Command {
+	var (
+		environment  string
+		blockName    string
+		revisionName string
+		images       map[string]string
+		steps        []int
+		interval     time.Duration
+		checks       int
+		probePath    string
+		resume       bool
+		abort        bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "rollout",
+		Short: "Progressively roll out a new revision to a service block",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			etcd, err := newEtcdClient()
+			if err != nil {
+				return err
+			}
+			defer etcd.Close()
+
+			client, err := newCloudRunClient(ctx)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			blocks, err := cloudrun.LoadServiceBlocks(ctx, client, environment)
+			if err != nil {
+				return err
+			}
+
+			block, found := blocks[blockName]
+			if !found {
+				return fmt.Errorf("block %s not found in environment %s", blockName, environment)
+			}
+
+			config := rollout.DefaultConfig()
+			config.Steps = steps
+			config.Interval = interval
+			config.Checks = checks
+			config.ProbePath = probePath
+
+			controller := rollout.NewController(client, rollout.NewEtcdStore(etcd), config)
+
+			switch {
+			case abort:
+				err = controller.Abort(ctx, environment, block)
+			case resume:
+				err = controller.Resume(ctx, environment, block)
+			default:
+				if revisionName == "" {
+					return fmt.Errorf("--revision is required to start a rollout")
+				}
+
+				var revision *cloudrun.Revision
+				revision, err = block.LatestRevision()
+				if err != nil {
+					return err
+				}
+
+				revision.Name = revisionName
+				for name, image := range images {
+					container, found := revision.Containers[name]
+					if !found {
+						return fmt.Errorf("container %s not found in revision", name)
+					}
+					container.Image = image
+					revision.Containers[name] = container
+				}
+
+				err = controller.Start(ctx, environment, block, revision)
+			}
+
+			fmt.Println()
+			for _, line := range block.Display() {
+				fmt.Println(line)
+			}
+
+			return err
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&environment, "environment", "e", "", "Name of the environment that contains the block")
+	cmd.PersistentFlags().StringVarP(&blockName, "block", "b", "", "Name of the service block")
+	cmd.PersistentFlags().StringVarP(&revisionName, "revision", "r", "", "Name of the new revision")
+	cmd.PersistentFlags().StringToStringVar(&images, "image", nil, "New image by container name (e.g. deno=gcr.io/project/deno:v2)")
+	cmd.PersistentFlags().IntSliceVar(&steps, "steps", []int{5, 25, 50, 100}, "Traffic percentages sent to the new revision at each step")
+	cmd.PersistentFlags().DurationVar(&interval, "interval", 30*time.Second, "Time between health checks")
+	cmd.PersistentFlags().IntVar(&checks, "checks", 3, "Number of health checks that must pass before the next step")
+	cmd.PersistentFlags().StringVar(&probePath, "probe-path", "/", "HTTP path probed on every service")
+	cmd.PersistentFlags().BoolVar(&resume, "resume", false, "Resume an interrupted rollout")
+	cmd.PersistentFlags().BoolVar(&abort, "abort", false, "Abort an in-progress rollout and restore the previous traffic split")
+
+	cmd.MarkPersistentFlagRequired("environment")
+	cmd.MarkPersistentFlagRequired("block")
+
+	return cmd
+}
@@ -27,6 +27,21 @@ func NewCmdRoot() *cobra.Command {
 	cmd.AddCommand(NewCmdList())
 	cmd.AddCommand(NewCmdDelete())
 	cmd.AddCommand(NewCmdUpdate())
+	cmd.AddCommand(NewCmdTraffic())
+	cmd.AddCommand(NewCmdTag())
+	cmd.AddCommand(NewCmdScale())
+	cmd.AddCommand(NewCmdRollout())
+	cmd.AddCommand(NewCmdRollback())
+	cmd.AddCommand(NewCmdGc())
+	cmd.AddCommand(NewCmdMigrate())
+	cmd.AddCommand(NewCmdOps())
+	cmd.AddCommand(NewCmdResume())
+	cmd.AddCommand(NewCmdWatch())
+	cmd.AddCommand(NewCmdDrift())
+	cmd.AddCommand(NewCmdPlan())
+	cmd.AddCommand(NewCmdApply())
+	cmd.AddCommand(NewCmdExport())
+	cmd.AddCommand(NewCmdInvokers())
 	cmd.AddCommand(NewCmdExecutor())
 	cmd.AddCommand(NewCmdRouter())
 
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/angelini/sblocks/internal/log"
+	"github.com/angelini/sblocks/pkg/cloudrun"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func NewCmdScale() *cobra.Command {
+	var (
+		environment    string
+		blockName      string
+		size           int
+		onlyUnassigned bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "scale",
+		Short: "Change the number of services in a block",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			client, err := newCloudRunClient(ctx)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			blocks, err := cloudrun.LoadServiceBlocks(ctx, client, environment)
+			if err != nil {
+				return err
+			}
+
+			block, found := blocks[blockName]
+			if !found {
+				return fmt.Errorf("block %s not found in environment %s", blockName, environment)
+			}
+
+			err = block.Resize(ctx, client, size, onlyUnassigned)
+			if err != nil {
+				return err
+			}
+
+			log.Info(ctx, "scaled service block", zap.String("block", blockName), zap.Int("size", size))
+
+			fmt.Println()
+			for _, line := range block.Display() {
+				fmt.Println(line)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&environment, "environment", "e", "", "Name of the environment that contains the block")
+	cmd.PersistentFlags().StringVarP(&blockName, "block", "b", "", "Name of the service block")
+	cmd.PersistentFlags().IntVarP(&size, "size", "s", 0, "Number of services the block should contain")
+	cmd.PersistentFlags().BoolVar(&onlyUnassigned, "only-unassigned", false, "Only remove services that are not assigned")
+
+	cmd.MarkPersistentFlagRequired("environment")
+	cmd.MarkPersistentFlagRequired("block")
+	cmd.MarkPersistentFlagRequired("size")
+
+	return cmd
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/angelini/sblocks/internal/maps"
+	"github.com/angelini/sblocks/pkg/cloudrun"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdTag() *cobra.Command {
+	var (
+		environment string
+		blockName   string
+		revision    string
+		tag         string
+		remove      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "tag",
+		Short: "Point a traffic tag at a revision of every service in a block",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			if !remove && revision == "" {
+				return fmt.Errorf("--revision is required unless --remove is set")
+			}
+
+			client, err := newCloudRunClient(ctx)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			blocks, err := cloudrun.LoadServiceBlocks(ctx, client, environment)
+			if err != nil {
+				return err
+			}
+
+			block, found := blocks[blockName]
+			if !found {
+				return fmt.Errorf("block %s not found in environment %s", blockName, environment)
+			}
+
+			if remove {
+				return block.RemoveTag(ctx, client, tag)
+			}
+
+			err = block.TagRevision(ctx, client, revision, tag)
+			if err != nil {
+				return err
+			}
+
+			uris := block.TagURIs(tag)
+			for _, serviceName := range maps.SortedKeys(uris) {
+				fmt.Printf("%s: %s\n", serviceName, uris[serviceName])
+			}
+
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&environment, "environment", "e", "", "Name of the environment that contains the block")
+	cmd.PersistentFlags().StringVarP(&blockName, "block", "b", "", "Name of the service block")
+	cmd.PersistentFlags().StringVarP(&revision, "revision", "r", "", "Name of the revision to tag")
+	cmd.PersistentFlags().StringVarP(&tag, "tag", "t", "", "Traffic tag, e.g. canary")
+	cmd.PersistentFlags().BoolVar(&remove, "remove", false, "Remove the tag instead of assigning it")
+
+	cmd.MarkPersistentFlagRequired("environment")
+	cmd.MarkPersistentFlagRequired("block")
+	cmd.MarkPersistentFlagRequired("tag")
+
+	return cmd
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/angelini/sblocks/internal/log"
+	"github.com/angelini/sblocks/pkg/cloudrun"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func NewCmdTraffic() *cobra.Command {
+	var (
+		environment string
+		blockName   string
+		split       map[string]int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "traffic",
+		Short: "Split traffic between revisions of a service block",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			client, err := newCloudRunClient(ctx)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			blocks, err := cloudrun.LoadServiceBlocks(ctx, client, environment)
+			if err != nil {
+				return err
+			}
+
+			block, found := blocks[blockName]
+			if !found {
+				return fmt.Errorf("block %s not found in environment %s", blockName, environment)
+			}
+
+			err = block.SetTraffic(ctx, client, split)
+			if err != nil {
+				return err
+			}
+
+			log.Info(ctx, "updated block traffic", zap.String("block", blockName), zap.Any("split", split))
+
+			fmt.Println()
+			for _, line := range block.Display() {
+				fmt.Println(line)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&environment, "environment", "e", "", "Name of the environment that contains the block")
+	cmd.PersistentFlags().StringVarP(&blockName, "block", "b", "", "Name of the service block")
+	cmd.PersistentFlags().StringToIntVar(&split, "split", nil, "Traffic split by revision name (e.g. 1=90,2=10)")
+
+	cmd.MarkPersistentFlagRequired("environment")
+	cmd.MarkPersistentFlagRequired("block")
+	cmd.MarkPersistentFlagRequired("split")
+
+	return cmd
+}
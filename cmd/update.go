@@ -1,9 +1,6 @@
 package cmd
 
 import (
-	"os"
-
-	"github.com/angelini/sblocks/pkg/cloudrun"
 	"github.com/spf13/cobra"
 )
 
@@ -14,7 +11,7 @@ func NewCmdUpdate() *cobra.Command {
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			ctx := cmd.Context()
 
-			client, err := cloudrun.NewClient(ctx, os.Getenv("GCP_PROJECT"), os.Getenv("GCP_REGION"))
+			client, err := newCloudRunClient(ctx)
 			if err != nil {
 				return err
 			}
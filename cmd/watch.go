@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/angelini/sblocks/pkg/cloudrun"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdWatch() *cobra.Command {
+	var (
+		environment string
+		blockName   string
+		interval    time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Print service and revision state changes as they happen",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			if interval <= 0 {
+				return fmt.Errorf("invalid --interval %s, must be positive", interval)
+			}
+
+			client, err := newCloudRunClient(ctx)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			blocks, err := cloudrun.LoadServiceBlocks(ctx, client, environment)
+			if err != nil {
+				return err
+			}
+
+			if blockName != "" {
+				block, found := blocks[blockName]
+				if !found {
+					return fmt.Errorf("block %s not found in environment %s", blockName, environment)
+				}
+				blocks = map[string]*cloudrun.ServiceBlock{blockName: block}
+			}
+
+			events := make(chan cloudrun.Event)
+			var wg sync.WaitGroup
+
+			for _, block := range blocks {
+				blockEvents, err := block.Watch(ctx, client, interval)
+				if err != nil {
+					return err
+				}
+
+				wg.Add(1)
+				go func(blockEvents <-chan cloudrun.Event) {
+					defer wg.Done()
+					for event := range blockEvents {
+						events <- event
+					}
+				}(blockEvents)
+			}
+
+			go func() {
+				wg.Wait()
+				close(events)
+			}()
+
+			for event := range events {
+				fmt.Println(event.String())
+			}
+
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&environment, "environment", "e", "", "Name of the environment to watch")
+	cmd.PersistentFlags().StringVarP(&blockName, "block", "b", "", "Only watch this service block")
+	cmd.PersistentFlags().DurationVar(&interval, "interval", 5*time.Second, "Time between polls")
+
+	cmd.MarkPersistentFlagRequired("environment")
+
+	return cmd
+}
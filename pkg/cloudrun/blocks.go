@@ -4,80 +4,272 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	pb "cloud.google.com/go/run/apiv2/runpb"
+	"github.com/angelini/sblocks/internal/log"
 	"github.com/angelini/sblocks/internal/maps"
-	"golang.org/x/sync/errgroup"
+	"go.uber.org/zap"
+)
+
+const (
+	EnvironmentLabel = "sb_environment"
+	ManagedLabel     = "sb_managed"
+	BlockLabel       = "sb_block"
+	IndexLabel       = "sb_index"
+	AssignedLabel    = "sb_assigned"
 )
 
 type RevisionInstance struct {
 	definition *Revision
 	labels     map[string]string
 	state      RevisionState
+	created    time.Time
 }
 
 type ServiceInstance struct {
 	name      string
+	index     int
+	region    string
+	labels    map[string]string
 	state     ServiceState
 	uri       string
+	ingress   Ingress
 	traffic   *TrafficStatus
 	revisions map[string]*RevisionInstance
+	pending   []Operation
+}
+
+func newServiceInstance(service *pb.Service) *ServiceInstance {
+	index, err := strconv.Atoi(service.Labels[IndexLabel])
+	if err != nil {
+		index = -1
+	}
+
+	return &ServiceInstance{
+		name:    ParseServiceName(service.Name),
+		index:   index,
+		region:  ParseLocation(service.Name),
+		labels:  service.Labels,
+		state:   GetServiceState(service),
+		uri:     service.Uri,
+		ingress: IngressDefinition(service.Ingress),
+		traffic: NewTrafficStatus(service.TrafficStatuses),
+	}
+}
+
+func (s *ServiceInstance) hasRevision(name string) bool {
+	for _, revision := range s.revisions {
+		if revision.definition.Name == name && !revision.state.isDeleted {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *ServiceInstance) currentTraffic() []TrafficTarget {
+	if s.traffic.latest {
+		return []TrafficTarget{{Revision: s.state.latestRevision, Percent: 100}}
+	}
+
+	targets := make([]TrafficTarget, 0, len(s.traffic.revisions))
+	for _, revision := range maps.SortedKeys(s.traffic.revisions) {
+		if s.traffic.revisions[revision] > 0 {
+			targets = append(targets, TrafficTarget{Revision: revision, Percent: s.traffic.revisions[revision]})
+		}
+	}
+	return targets
+}
+
+func (s *ServiceInstance) tagRevisions() map[string]string {
+	tags := make(map[string]string, len(s.traffic.tags))
+	for tag, target := range s.traffic.tags {
+		tags[tag] = target.Revision
+	}
+	return tags
+}
+
+// withTags puts each tag on the target of its revision, adding a 0 percent target for
+// revisions that receive no traffic.
+func withTags(targets []TrafficTarget, tags map[string]string) []TrafficTarget {
+	for _, tag := range maps.SortedKeys(tags) {
+		merged := false
+		for idx := range targets {
+			if !targets[idx].Latest && targets[idx].Revision == tags[tag] && targets[idx].Tag == "" {
+				targets[idx].Tag = tag
+				merged = true
+				break
+			}
+		}
+
+		if !merged {
+			targets = append(targets, TrafficTarget{Revision: tags[tag], Tag: tag})
+		}
+	}
+	return targets
+}
+
+// splitTargets converts a split by revision name into traffic targets that keep the
+// service's tags.
+func (s *ServiceInstance) splitTargets(split map[string]int) []TrafficTarget {
+	targets := make([]TrafficTarget, 0, len(split))
+	for _, revision := range maps.SortedKeys(split) {
+		targets = append(targets, TrafficTarget{
+			Revision: RevisionID(s.name, revision),
+			Percent:  int32(split[revision]),
+		})
+	}
+	return withTags(targets, s.tagRevisions())
+}
+
+func (s *ServiceInstance) trafficSplit() map[string]int {
+	split := make(map[string]int)
+	for _, revision := range s.revisions {
+		if revision.state.traffic > 0 {
+			split[revision.definition.Name] = revision.state.traffic
+		}
+	}
+	return split
+}
+
+// pruneCandidates lists the revisions outside of the newest keep that are neither
+// receiving traffic nor the latest ready revision.
+func (s *ServiceInstance) pruneCandidates(keep int) []string {
+	revisions := maps.SortedValues(s.revisions)
+	sort.SliceStable(revisions, func(i, j int) bool {
+		return revisions[i].created.After(revisions[j].created)
+	})
+
+	tagged := make(map[string]bool, len(s.traffic.tags))
+	for _, target := range s.traffic.tags {
+		tagged[target.Revision] = true
+	}
+
+	candidates := []string{}
+	for idx, revision := range revisions {
+		if idx < keep || revision.state.traffic > 0 || revision.state.isDeleted {
+			continue
+		}
+
+		if tagged[RevisionID(s.name, revision.definition.Name)] {
+			continue
+		}
+
+		if RevisionID(s.name, revision.definition.Name) == s.state.latestRevision {
+			continue
+		}
+
+		candidates = append(candidates, revision.definition.Name)
+	}
+
+	return candidates
+}
+
+func (s *ServiceInstance) isAssigned() bool {
+	_, found := s.labels[AssignedLabel]
+	return found
 }
 
 type ServiceBlock struct {
 	name     string
-	public   bool
+	invokers []string
 	labels   map[string]string
 	services map[string]*ServiceInstance
 }
 
-func CreateServiceBlock(ctx context.Context, client *Client, public bool, size int, labels map[string]string, revision *Revision) (*ServiceBlock, error) {
-	services := make(map[string]*ServiceInstance, size)
-	name := randomString(6)
+// PartialBlockError is returned when block creation failed after some services were
+// created and keepPartial was set. Resizing the block to Size resumes the creation.
+type PartialBlockError struct {
+	Block *ServiceBlock
+	Size  int
+	Err   error
+}
 
-	{
-		group, ctx := errgroup.WithContext(ctx)
+func (e *PartialBlockError) Error() string {
+	return fmt.Sprintf("block %s created with %d of %d services: %v", e.Block.name, len(e.Block.services), e.Size, e.Err)
+}
 
-		for i := 0; i < size; i++ {
-			serviceName := fmt.Sprintf("%s-%d", name, i)
-			group.Go(func() error {
-				service, err := client.Create(ctx, serviceName, labels, revision)
-				if err != nil {
-					return err
-				}
+func (e *PartialBlockError) Unwrap() error {
+	return e.Err
+}
 
-				if public {
-					err = client.AllowPublicAccess(ctx, serviceName)
-					if err != nil {
-						return err
-					}
-				}
+// CreateServiceBlock creates every service of the block or none of them: on failure the
+// services created so far are deleted, unless keepPartial is set in which case they are
+// returned in a PartialBlockError.
+func CreateServiceBlock(ctx context.Context, client Backend, public bool, size int, labels map[string]string, revision *Revision, keepPartial bool) (*ServiceBlock, error) {
+	err := revision.Validate()
+	if err != nil {
+		return nil, err
+	}
 
-				services[serviceName] = &ServiceInstance{
-					name:    serviceName,
-					uri:     service.Uri,
-					state:   GetServiceState(service),
-					traffic: NewTrafficStatus(service.TrafficStatuses),
-				}
-				return nil
-			})
-		}
+	name, err := newBlockName(ctx, client)
+	if err != nil {
+		return nil, err
+	}
 
-		err := group.Wait()
-		if err != nil {
-			return nil, err
-		}
+	return createServiceBlock(ctx, client, name, public, size, labels, revision, keepPartial)
+}
+
+// CreateNamedServiceBlock creates a block with a chosen name, failing if any existing
+// service already uses it.
+func CreateNamedServiceBlock(ctx context.Context, client Backend, name string, public bool, size int, labels map[string]string, revision *Revision, keepPartial bool) (*ServiceBlock, error) {
+	err := revision.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	services, err := client.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if blockNameUsed(services, name) {
+		return nil, fmt.Errorf("block name %s is already in use", name)
 	}
 
+	return createServiceBlock(ctx, client, name, public, size, labels, revision, keepPartial)
+}
+
+func createServiceBlock(ctx context.Context, client Backend, name string, public bool, size int, labels map[string]string, revision *Revision, keepPartial bool) (*ServiceBlock, error) {
 	sb := ServiceBlock{
-		name,
-		public,
-		labels,
-		services,
+		name:     name,
+		labels:   blockLabels(name, labels),
+		services: make(map[string]*ServiceInstance, size),
+	}
+	if public {
+		sb.invokers = []string{AllUsers}
+	}
+
+	indexes := make([]int, 0, size)
+	for i := 0; i < size; i++ {
+		indexes = append(indexes, i)
+	}
+
+	err := sb.createServices(ctx, client, revision, indexes)
+	if err != nil {
+		if keepPartial && len(sb.services) > 0 {
+			log.Warn(ctx, "keep partial block", zap.String("block", name), zap.Int("created", len(sb.services)), zap.Error(err))
+
+			// load the revisions so that the returned block can be resized to resume
+			refreshErr := sb.Refresh(ctx, client)
+			if refreshErr != nil {
+				log.Warn(ctx, "failed to refresh partial block", zap.String("block", name), zap.Error(refreshErr))
+			}
+			return nil, &PartialBlockError{Block: &sb, Size: size, Err: err}
+		}
+
+		rollbackErr := sb.rollback(ctx, client)
+		if rollbackErr != nil {
+			return nil, fmt.Errorf("rollback of block %s after %v failed: %w", name, err, rollbackErr)
+		}
+		return nil, err
 	}
 
-	err := sb.loadRevisions(ctx, client)
+	err = sb.loadRevisions(ctx, client)
 	if err != nil {
 		return nil, err
 	}
@@ -85,39 +277,66 @@ func CreateServiceBlock(ctx context.Context, client *Client, public bool, size i
 	return &sb, nil
 }
 
-func LoadServiceBlocks(ctx context.Context, client *Client, environment string) (map[string]*ServiceBlock, error) {
+// rollback deletes every service labelled with the block name, including creates that
+// completed server side after their call had already been cancelled.
+func (sb *ServiceBlock) rollback(ctx context.Context, client Backend) error {
+	serviceNames, err := FindDeletable(ctx, client, DeleteScope{Block: sb.name})
+	if err != nil {
+		return err
+	}
+
+	if len(serviceNames) == 0 {
+		return nil
+	}
+
+	log.Warn(ctx, "rollback partial block", zap.String("block", sb.name), zap.Strings("services", serviceNames))
+	return DeleteServices(ctx, client, serviceNames)
+}
+
+func LoadServiceBlocks(ctx context.Context, client Backend, environment string) (map[string]*ServiceBlock, error) {
 	services, err := client.List(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	operations, err := client.PendingOperations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make(map[string][]Operation)
+	for _, operation := range operations {
+		pending[operation.Service] = append(pending[operation.Service], operation)
+	}
+
 	blocks := make(map[string]*ServiceBlock)
 
 	for _, service := range services {
-		envLabel, found := service.Labels["sb_environment"]
+		envLabel, found := service.Labels[EnvironmentLabel]
 		if !found || envLabel != environment {
 			continue
 		}
 
 		serviceName := ParseServiceName(service.Name)
-		blockName := strings.Split(serviceName, "-")[0]
+		blockName, found := service.Labels[BlockLabel]
+		if !found {
+			log.Warn(ctx, "skip service without block label", zap.String("service", serviceName))
+			continue
+		}
 
 		block, found := blocks[blockName]
 		if !found {
 			block = &ServiceBlock{
 				name:     blockName,
-				labels:   service.Labels,
+				labels:   blockLabels(blockName, service.Labels),
 				services: make(map[string]*ServiceInstance),
 			}
 			blocks[blockName] = block
 		}
 
-		block.services[serviceName] = &ServiceInstance{
-			name:    serviceName,
-			state:   GetServiceState(service),
-			uri:     service.Uri,
-			traffic: NewTrafficStatus(service.TrafficStatuses),
-		}
+		instance := newServiceInstance(service)
+		instance.pending = pending[serviceName]
+		block.services[serviceName] = instance
 	}
 
 	for _, block := range blocks {
@@ -130,8 +349,8 @@ func LoadServiceBlocks(ctx context.Context, client *Client, environment string)
 	return blocks, nil
 }
 
-func (sb *ServiceBlock) loadRevisions(ctx context.Context, client *Client) error {
-	group, ctx := errgroup.WithContext(ctx)
+func (sb *ServiceBlock) loadRevisions(ctx context.Context, client Backend) error {
+	group, ctx := newGroup(ctx, client)
 
 	for _, service := range sb.services {
 		service := service
@@ -147,7 +366,7 @@ func (sb *ServiceBlock) loadRevisions(ctx context.Context, client *Client) error
 				if idx == 0 && service.traffic.latest {
 					percentage = 100
 				} else {
-					percentage = int(service.traffic.revisions[revision.Name])
+					percentage = int(service.traffic.revisions[ParseRevisionName(revision.Name)])
 				}
 
 				definition := RevisionDefinition(revision)
@@ -155,6 +374,7 @@ func (sb *ServiceBlock) loadRevisions(ctx context.Context, client *Client) error
 					definition: &definition,
 					labels:     revision.Labels,
 					state:      GetRevisionState(revision, percentage),
+					created:    revision.CreateTime.AsTime(),
 				}
 			}
 
@@ -166,13 +386,52 @@ func (sb *ServiceBlock) loadRevisions(ctx context.Context, client *Client) error
 	return group.Wait()
 }
 
-func (sb *ServiceBlock) CreateRevision(ctx context.Context, client *Client, revision *Revision) error {
-	group, ctx := errgroup.WithContext(ctx)
+// CreateRevision deploys a revision to every service and moves all traffic to it. With a
+// tag, the revision is deployed without traffic and only reachable through the tag URLs.
+func (sb *ServiceBlock) CreateRevision(ctx context.Context, client Backend, revision *Revision, tag string) error {
+	if tag != "" {
+		return sb.deployRevision(ctx, client, revision, true, tag)
+	}
+	return sb.deployRevision(ctx, client, revision, false, "")
+}
+
+// StageRevision deploys a revision to every service without moving any traffic to it.
+func (sb *ServiceBlock) StageRevision(ctx context.Context, client Backend, revision *Revision) error {
+	return sb.deployRevision(ctx, client, revision, true, "")
+}
+
+func (sb *ServiceBlock) deployRevision(ctx context.Context, client Backend, revision *Revision, keepTraffic bool, tag string) error {
+	err := revision.Validate()
+	if err != nil {
+		return err
+	}
+
+	if tag != "" {
+		err = validateTag(tag)
+		if err != nil {
+			return err
+		}
+	}
+
+	group, groupCtx := newGroup(ctx, client)
 
 	for _, service := range sb.services {
 		service := service
+
+		tags := service.tagRevisions()
+		if tag != "" {
+			tags[tag] = RevisionID(service.name, revision.Name)
+		}
+
+		var traffic []TrafficTarget
+		if keepTraffic {
+			traffic = withTags(service.currentTraffic(), tags)
+		} else if len(tags) > 0 {
+			traffic = withTags([]TrafficTarget{{Latest: true, Percent: 100}}, tags)
+		}
+
 		group.Go(func() error {
-			err := client.Update(ctx, service.name, sb.labels, revision)
+			err := client.Update(groupCtx, service.name, sb.serviceLabels(service.index, service.labels), revision, traffic)
 			if err != nil {
 				return err
 			}
@@ -181,12 +440,12 @@ func (sb *ServiceBlock) CreateRevision(ctx context.Context, client *Client, revi
 		})
 	}
 
-	err := group.Wait()
+	err = group.Wait()
 	if err != nil {
 		return err
 	}
 
-	err = sb.loadRevisions(ctx, client)
+	err = sb.Refresh(ctx, client)
 	if err != nil {
 		return err
 	}
@@ -194,37 +453,771 @@ func (sb *ServiceBlock) CreateRevision(ctx context.Context, client *Client, revi
 	return nil
 }
 
-func (sb *ServiceBlock) Display() []string {
-	results := []string{
-		fmt.Sprintf("%s [%s]:", sb.name, formatLabels(sb.labels)),
+func (sb *ServiceBlock) Refresh(ctx context.Context, client Backend) error {
+	services, err := client.List(ctx)
+	if err != nil {
+		return err
 	}
 
-	for _, service := range maps.SortedValues(sb.services) {
-		results = append(results, fmt.Sprintf("  > %s: %s", service.name, service.state.String()))
-		results = append(results, fmt.Sprintf("    uri: %s", service.uri))
-		for _, revision := range maps.SortedValues(service.revisions) {
-			results = append(results, fmt.Sprintf(
-				"    - %s[%s]: %s",
-				strings.TrimPrefix(revision.definition.Name, service.name+"-"),
-				formatLabels(revision.labels),
-				revision.state.String(),
-			))
+	for _, service := range services {
+		existing, found := sb.services[ParseServiceName(service.Name)]
+		if !found {
+			continue
 		}
+
+		reloaded := newServiceInstance(service)
+		existing.index = reloaded.index
+		existing.labels = reloaded.labels
+		existing.state = reloaded.state
+		existing.uri = reloaded.uri
+		existing.ingress = reloaded.ingress
+		existing.traffic = reloaded.traffic
 	}
 
-	return results
+	return sb.loadRevisions(ctx, client)
 }
 
-func formatLabels(labels map[string]string) string {
-	entries := make([]string, 0, len(labels))
-	for _, key := range maps.SortedKeys(labels) {
-		entries = append(entries, fmt.Sprintf("%s=%s", key, labels[key]))
+// Resize grows the block with services that copy its traffic split and tags, or shrinks
+// it by deleting the highest indexed services. With onlyUnassigned set, services
+// labelled as assigned are never removed.
+func (sb *ServiceBlock) Resize(ctx context.Context, client Backend, size int, onlyUnassigned bool) error {
+	if size < 1 {
+		return fmt.Errorf("block size must be at least 1, found %d", size)
 	}
 
-	return strings.Join(entries, ", ")
+	switch {
+	case size > len(sb.services):
+		return sb.grow(ctx, client, size-len(sb.services))
+	case size < len(sb.services):
+		return sb.shrink(ctx, client, len(sb.services)-size, onlyUnassigned)
+	}
+
+	return nil
 }
 
-var letterRunes = []rune("abcdefghijklmnopqrstuvwxyz")
+func (sb *ServiceBlock) grow(ctx context.Context, client Backend, count int) error {
+	revision, err := sb.LatestRevision()
+	if err != nil {
+		return err
+	}
+
+	existing := maps.SortedValues(sb.services)[0]
+
+	split, err := sb.TrafficSplit()
+	if err != nil {
+		return err
+	}
+
+	// tags point at revision IDs, which differ on every service, so keep the revision names
+	tags := make(map[string]string)
+	tagged := make(map[string]bool)
+	for tag, revisionID := range existing.tagRevisions() {
+		for _, instance := range existing.revisions {
+			if RevisionID(existing.name, instance.definition.Name) == revisionID {
+				tags[tag] = instance.definition.Name
+				tagged[instance.definition.Name] = true
+			}
+		}
+	}
+
+	// deploy the other revisions serving traffic or tagged first, oldest to newest, so
+	// that the latest revision stays the latest on the new services
+	serving := maps.SortedValues(existing.revisions)
+	sort.SliceStable(serving, func(i, j int) bool {
+		return serving[i].created.Before(serving[j].created)
+	})
+
+	deploys := []*Revision{}
+	for _, instance := range serving {
+		name := instance.definition.Name
+		if (split[name] == 0 && !tagged[name]) || name == revision.Name {
+			continue
+		}
+
+		definition := *instance.definition
+		definition.Ingress = existing.ingress
+		deploys = append(deploys, &definition)
+	}
+	deploys = append(deploys, revision)
+
+	policy, err := client.GetIamPolicy(ctx, existing.name)
+	if err != nil {
+		return err
+	}
+	sb.invokers = invokerMembers(policy)
+
+	used := make(map[int]bool, len(sb.services))
+	for _, service := range sb.services {
+		used[service.index] = true
+	}
+
+	indexes := make([]int, 0, count)
+	for index := 0; len(indexes) < count; index++ {
+		if !used[index] {
+			indexes = append(indexes, index)
+		}
+	}
+
+	err = sb.createServices(ctx, client, deploys[0], indexes)
+	if err != nil {
+		return err
+	}
+
+	if len(split) != 1 || split[revision.Name] != 100 || len(tags) > 0 {
+		err = sb.copyTraffic(ctx, client, indexes, deploys[1:], split, tags)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Info(ctx, "grew service block", zap.String("block", sb.name), zap.Ints("indexes", indexes))
+	return sb.Refresh(ctx, client)
+}
+
+// copyTraffic deploys the remaining revisions to the new services and applies the
+// block's traffic split and tags, given by revision name, to them.
+func (sb *ServiceBlock) copyTraffic(ctx context.Context, client Backend, indexes []int, revisions []*Revision, split map[string]int, tags map[string]string) error {
+	group, groupCtx := newGroup(ctx, client)
+
+	for _, index := range indexes {
+		index := index
+		service := sb.services[fmt.Sprintf("%s-%d", sb.name, index)]
+
+		group.Go(func() error {
+			for _, revision := range revisions {
+				err := client.Update(groupCtx, service.name, sb.serviceLabels(index, nil), revision, nil)
+				if err != nil {
+					return err
+				}
+			}
+
+			serviceTags := make(map[string]string, len(tags))
+			for tag, revision := range tags {
+				serviceTags[tag] = RevisionID(service.name, revision)
+			}
+
+			return client.SetTraffic(groupCtx, service.name, withTags(service.splitTargets(split), serviceTags))
+		})
+	}
+
+	return group.Wait()
+}
+
+// createServices creates a service for every index, adding each to the block as soon as
+// it exists so that a failure leaves an accurate record of what was created.
+func (sb *ServiceBlock) createServices(ctx context.Context, client Backend, revision *Revision, indexes []int) error {
+	var mu sync.Mutex
+	group, groupCtx := newGroup(ctx, client)
+
+	for _, index := range indexes {
+		serviceName, serviceLabels := fmt.Sprintf("%s-%d", sb.name, index), sb.serviceLabels(index, nil)
+		group.Go(func() error {
+			service, err := client.Create(groupCtx, serviceName, serviceLabels, revision)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			sb.services[serviceName] = newServiceInstance(service)
+			mu.Unlock()
+
+			if len(sb.invokers) > 0 {
+				return updateInvokers(groupCtx, client, serviceName, sb.invokers, nil)
+			}
+			return nil
+		})
+	}
+
+	return group.Wait()
+}
+
+func (sb *ServiceBlock) shrink(ctx context.Context, client Backend, count int, onlyUnassigned bool) error {
+	services := maps.SortedValues(sb.services)
+	sort.SliceStable(services, func(i, j int) bool {
+		return services[i].index > services[j].index
+	})
+
+	serviceNames := make([]string, 0, count)
+	for _, service := range services {
+		if len(serviceNames) == count {
+			break
+		}
+
+		if onlyUnassigned && service.isAssigned() {
+			continue
+		}
+
+		serviceNames = append(serviceNames, service.name)
+	}
+
+	if len(serviceNames) < count {
+		return fmt.Errorf("cannot remove %d services from block %s, only %d are unassigned", count, sb.name, len(serviceNames))
+	}
+
+	err := DeleteServices(ctx, client, serviceNames)
+	if err != nil {
+		return err
+	}
+
+	for _, serviceName := range serviceNames {
+		delete(sb.services, serviceName)
+	}
+
+	log.Info(ctx, "shrank service block", zap.String("block", sb.name), zap.Strings("services", serviceNames))
+	return nil
+}
+
+func (sb *ServiceBlock) Name() string {
+	return sb.name
+}
+
+func (sb *ServiceBlock) Size() int {
+	return len(sb.services)
+}
+
+// UserLabels returns the block labels that were not added by sblocks itself.
+func (sb *ServiceBlock) UserLabels() map[string]string {
+	labels := make(map[string]string)
+	for key, value := range sb.labels {
+		if !strings.HasPrefix(key, "sb_") {
+			labels[key] = value
+		}
+	}
+	return labels
+}
+
+// SetLabels replaces the user labels of every service, keeping the sblocks labels.
+func (sb *ServiceBlock) SetLabels(ctx context.Context, client Backend, labels map[string]string) error {
+	merged := make(map[string]string, len(labels)+1)
+	for key, value := range labels {
+		merged[key] = value
+	}
+	if environment, found := sb.labels[EnvironmentLabel]; found {
+		merged[EnvironmentLabel] = environment
+	}
+	sb.labels = blockLabels(sb.name, merged)
+
+	group, groupCtx := newGroup(ctx, client)
+
+	for _, service := range sb.services {
+		serviceName, serviceLabels := service.name, sb.serviceLabels(service.index, service.labels)
+		group.Go(func() error {
+			return client.SetLabels(groupCtx, serviceName, serviceLabels)
+		})
+	}
+
+	err := group.Wait()
+	if err != nil {
+		return err
+	}
+
+	return sb.Refresh(ctx, client)
+}
+
+// IsPublic reports whether every service in the block allows unauthenticated access.
+func (sb *ServiceBlock) IsPublic(ctx context.Context, client Backend) (bool, error) {
+	for _, service := range maps.SortedValues(sb.services) {
+		policy, err := client.GetIamPolicy(ctx, service.name)
+		if err != nil {
+			return false, err
+		}
+
+		if !isPublicPolicy(policy) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (sb *ServiceBlock) URIs() map[string]string {
+	uris := make(map[string]string, len(sb.services))
+	for name, service := range sb.services {
+		uris[name] = service.uri
+	}
+	return uris
+}
+
+// TrafficSplit returns the revision to percent split shared by every service in the block.
+func (sb *ServiceBlock) TrafficSplit() (map[string]int, error) {
+	var result map[string]int
+
+	for _, service := range maps.SortedValues(sb.services) {
+		split := service.trafficSplit()
+		if result == nil {
+			result = split
+			continue
+		}
+
+		if len(split) != len(result) {
+			return nil, fmt.Errorf("traffic split on service %s differs from the rest of the block", service.name)
+		}
+		for revision, percent := range split {
+			if result[revision] != percent {
+				return nil, fmt.Errorf("traffic split on service %s differs from the rest of the block", service.name)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// LatestRevision returns a copy of the latest ready revision's definition, to be used
+// as the base of the next revision.
+func (sb *ServiceBlock) LatestRevision() (*Revision, error) {
+	for _, service := range maps.SortedValues(sb.services) {
+		for _, instance := range service.revisions {
+			if RevisionID(service.name, instance.definition.Name) != service.state.latestRevision {
+				continue
+			}
+
+			// the ingress lives on the service, leaving it empty would open the next
+			// revision to all traffic
+			revision := *instance.definition
+			revision.Ingress = service.ingress
+			revision.Containers = make(map[string]Container, len(instance.definition.Containers))
+			for name, container := range instance.definition.Containers {
+				revision.Containers[name] = container
+			}
+			return &revision, nil
+		}
+	}
+
+	return nil, fmt.Errorf("block %s has no ready revision", sb.name)
+}
+
+func (sb *ServiceBlock) HasRevision(name string) bool {
+	for _, service := range sb.services {
+		if !service.hasRevision(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckReady returns an error describing the first service that is not ready or that
+// has not finished deploying the named revision.
+func (sb *ServiceBlock) CheckReady(revision string) error {
+	for _, service := range maps.SortedValues(sb.services) {
+		if !service.state.isReady || service.state.isReconciling {
+			return fmt.Errorf("service %s is %s%s", service.name, service.state.String(), formatProblems(service.state.conditions))
+		}
+
+		var state *RevisionState
+		for _, instance := range service.revisions {
+			if instance.definition.Name == revision {
+				state = &instance.state
+			}
+		}
+
+		if state == nil || !state.isReady {
+			reasons := ""
+			if state != nil {
+				reasons = formatProblems(state.conditions)
+			}
+			return fmt.Errorf("revision %s is not ready on service %s%s", revision, service.name, reasons)
+		}
+	}
+
+	return nil
+}
+
+func (sb *ServiceBlock) SetTraffic(ctx context.Context, client Backend, split map[string]int) error {
+	total := 0
+	for revision, percent := range split {
+		if percent < 0 || percent > 100 {
+			return fmt.Errorf("invalid traffic percentage %d for revision %s", percent, revision)
+		}
+		total += percent
+	}
+
+	if total != 100 {
+		return fmt.Errorf("traffic percentages sum to %d, expected 100", total)
+	}
+
+	err := sb.Refresh(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	for _, service := range maps.SortedValues(sb.services) {
+		for _, revision := range maps.SortedKeys(split) {
+			if !service.hasRevision(revision) {
+				return fmt.Errorf("revision %s not found on service %s", revision, service.name)
+			}
+		}
+	}
+
+	group, groupCtx := newGroup(ctx, client)
+
+	for _, service := range sb.services {
+		service := service
+		targets := service.splitTargets(split)
+
+		group.Go(func() error {
+			return client.SetTraffic(groupCtx, service.name, targets)
+		})
+	}
+
+	err = group.Wait()
+	if err != nil {
+		return err
+	}
+
+	return sb.Refresh(ctx, client)
+}
+
+// PruneRevisions deletes every revision beyond the newest keep on each service, skipping
+// revisions that receive traffic. With dryRun set the candidates are returned but not deleted.
+func (sb *ServiceBlock) PruneRevisions(ctx context.Context, client Backend, keep int, dryRun bool) (map[string][]string, error) {
+	if keep < 1 {
+		return nil, fmt.Errorf("must keep at least 1 revision, found %d", keep)
+	}
+
+	pruned := make(map[string][]string)
+	for _, service := range sb.services {
+		candidates := service.pruneCandidates(keep)
+		if len(candidates) > 0 {
+			pruned[service.name] = candidates
+		}
+	}
+
+	if dryRun {
+		return pruned, nil
+	}
+
+	group, groupCtx := newGroup(ctx, client)
+
+	for serviceName, revisions := range pruned {
+		for _, revision := range revisions {
+			serviceName, revisionID := serviceName, RevisionID(serviceName, revision)
+			group.Go(func() error {
+				return client.DeleteRevision(groupCtx, serviceName, revisionID)
+			})
+		}
+	}
+
+	err := group.Wait()
+	if err != nil {
+		return pruned, err
+	}
+
+	return pruned, sb.Refresh(ctx, client)
+}
+
+type DeleteScope struct {
+	Environment string
+	Block       string
+}
+
+func (s *DeleteScope) matches(service *pb.Service) bool {
+	if s.Environment != "" && service.Labels[EnvironmentLabel] != s.Environment {
+		return false
+	}
+
+	if s.Block != "" && service.Labels[BlockLabel] != s.Block {
+		return false
+	}
+
+	return true
+}
+
+// FindDeletable lists the services within scope that were created by sblocks. Services
+// missing the management label are never returned, even if they match the scope.
+func FindDeletable(ctx context.Context, client Backend, scope DeleteScope) ([]string, error) {
+	if scope.Environment == "" && scope.Block == "" {
+		return nil, fmt.Errorf("delete requires an environment or a block")
+	}
+
+	services, err := client.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []string{}
+	for _, service := range services {
+		if !scope.matches(service) {
+			continue
+		}
+
+		serviceName := ParseServiceName(service.Name)
+		if service.Labels[ManagedLabel] != "true" {
+			log.Warn(ctx, "skip unmanaged service", zap.String("service", serviceName))
+			continue
+		}
+
+		results = append(results, serviceName)
+	}
+
+	sort.Strings(results)
+	return results, nil
+}
+
+// MigrateLabels stamps the block identity labels on services of the environment that
+// were created before blocks were labelled, deriving the block and index from the
+// "<block>-<index>" service name. Services are recognised by the environment label
+// alone, as updates made by older versions cleared the managed description.
+func MigrateLabels(ctx context.Context, client Backend, environment string, dryRun bool) (map[string]map[string]string, error) {
+	services, err := client.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	migrated := make(map[string]map[string]string)
+	for _, service := range services {
+		if service.Labels[EnvironmentLabel] != environment {
+			continue
+		}
+
+		if _, found := service.Labels[BlockLabel]; found {
+			continue
+		}
+
+		serviceName := ParseServiceName(service.Name)
+		separator := strings.LastIndex(serviceName, "-")
+		index, err := strconv.Atoi(serviceName[separator+1:])
+		if separator < 1 || err != nil {
+			log.Warn(ctx, "skip service with unexpected name", zap.String("service", serviceName))
+			continue
+		}
+
+		labels := blockLabels(serviceName[:separator], service.Labels)
+		labels[IndexLabel] = strconv.Itoa(index)
+		if assigned, found := service.Labels[AssignedLabel]; found {
+			labels[AssignedLabel] = assigned
+		}
+		migrated[serviceName] = labels
+	}
+
+	if dryRun {
+		return migrated, nil
+	}
+
+	group, groupCtx := newGroup(ctx, client)
+
+	for serviceName, labels := range migrated {
+		serviceName, labels := serviceName, labels
+		group.Go(func() error {
+			return client.SetLabels(groupCtx, serviceName, labels)
+		})
+	}
+
+	return migrated, group.Wait()
+}
+
+func DeleteServices(ctx context.Context, client Backend, serviceNames []string) error {
+	group, ctx := newGroup(ctx, client)
+
+	for _, serviceName := range serviceNames {
+		serviceName := serviceName
+		group.Go(func() error {
+			return client.Delete(ctx, serviceName)
+		})
+	}
+
+	return group.Wait()
+}
+
+func (sb *ServiceBlock) Display() []string {
+	results := []string{
+		fmt.Sprintf("%s [%s]:", sb.name, FormatLabels(sb.labels)),
+	}
+
+	for _, service := range maps.SortedValues(sb.services) {
+		state := service.state.String()
+		if len(service.pending) > 0 {
+			state = "PENDING"
+		}
+
+		results = append(results, fmt.Sprintf("  > %s: %s", service.name, state))
+		for _, operation := range service.pending {
+			results = append(results, fmt.Sprintf("    pending: %s", operation.String()))
+		}
+		for _, condition := range problems(service.state.conditions) {
+			results = append(results, fmt.Sprintf("    condition: %s", condition.String()))
+		}
+		results = append(results, fmt.Sprintf("    region: %s", service.region))
+		results = append(results, fmt.Sprintf("    uri: %s", service.uri))
+		results = append(results, fmt.Sprintf("    ingress: %s", service.ingress))
+		for _, tag := range maps.SortedKeys(service.traffic.tags) {
+			target := service.traffic.tags[tag]
+			revision := strings.TrimPrefix(target.Revision, service.name+"-")
+			results = append(results, fmt.Sprintf("    tag %s: %s %s", tag, revision, target.URI))
+		}
+		for _, revision := range maps.SortedValues(service.revisions) {
+			results = append(results, displayRevision(revision)...)
+		}
+	}
+
+	return results
+}
+
+func displayRevision(revision *RevisionInstance) []string {
+	definition := revision.definition
+	results := []string{
+		fmt.Sprintf("    - %s[%s]: %s", definition.Name, FormatLabels(revision.labels), revision.state.String()),
+	}
+
+	if !revision.state.isDeleted {
+		for _, condition := range problems(revision.state.conditions) {
+			results = append(results, fmt.Sprintf("      condition: %s", condition.String()))
+		}
+	}
+
+	if definition.ServiceAccount != "" {
+		results = append(results, fmt.Sprintf("      service-account: %s", definition.ServiceAccount))
+	}
+
+	if definition.VPCAccess != nil {
+		results = append(results, fmt.Sprintf("      vpc: %s", definition.VPCAccess.String()))
+	}
+
+	if definition.StartupCPUBoost {
+		results = append(results, "      startup-cpu-boost: enabled")
+	}
+
+	for _, volume := range maps.SortedValues(definition.Volumes) {
+		results = append(results, fmt.Sprintf("      volume: %s", volume.String()))
+	}
+
+	for _, container := range maps.SortedValues(definition.Containers) {
+		results = append(results, displayContainer(container)...)
+	}
+
+	return results
+}
+
+func displayContainer(container Container) []string {
+	results := []string{
+		fmt.Sprintf("      %s: %s", container.Name, container.Image),
+		fmt.Sprintf("        resources: %s", container.Resources.String()),
+	}
+
+	if len(container.Env) > 0 || len(container.SecretEnv) > 0 {
+		results = append(results, fmt.Sprintf("        env: %s", formatEnv(container)))
+	}
+
+	for _, port := range container.Ports {
+		results = append(results, fmt.Sprintf("        port: %s", port.String()))
+	}
+
+	for _, mount := range container.VolumeMounts {
+		results = append(results, fmt.Sprintf("        mount: %s -> %s", mount.Volume, mount.Path))
+	}
+
+	if container.StartupProbe != nil {
+		results = append(results, fmt.Sprintf("        startup-probe: %s", container.StartupProbe.String()))
+	}
+
+	if container.LivenessProbe != nil {
+		results = append(results, fmt.Sprintf("        liveness-probe: %s", container.LivenessProbe.String()))
+	}
+
+	return results
+}
+
+func formatEnv(container Container) string {
+	entries := make([]string, 0, len(container.Env)+len(container.SecretEnv))
+	for _, key := range maps.SortedKeys(container.Env) {
+		entries = append(entries, fmt.Sprintf("%s=%s", key, container.Env[key]))
+	}
+
+	for _, key := range maps.SortedKeys(container.SecretEnv) {
+		ref := container.SecretEnv[key]
+		entries = append(entries, fmt.Sprintf("%s=<redacted:%s>", key, ref.String()))
+	}
+
+	return strings.Join(entries, ", ")
+}
+
+func formatProblems(conditions []Condition) string {
+	entries := []string{}
+	for _, condition := range problems(conditions) {
+		entries = append(entries, condition.String())
+	}
+
+	if len(entries) == 0 {
+		return ""
+	}
+	return ": " + strings.Join(entries, "; ")
+}
+
+// FormatLabels renders labels as sorted "key=value" pairs.
+func FormatLabels(labels map[string]string) string {
+	entries := make([]string, 0, len(labels))
+	for _, key := range maps.SortedKeys(labels) {
+		entries = append(entries, fmt.Sprintf("%s=%s", key, labels[key]))
+	}
+
+	return strings.Join(entries, ", ")
+}
+
+var letterRunes = []rune("abcdefghijklmnopqrstuvwxyz")
+
+// blockLabels strips the per service labels and stamps the block identity, leaving the
+// labels shared by every service in the block.
+func blockLabels(name string, labels map[string]string) map[string]string {
+	result := make(map[string]string, len(labels)+2)
+	for key, value := range labels {
+		if key != IndexLabel && key != AssignedLabel {
+			result[key] = value
+		}
+	}
+	result[ManagedLabel] = "true"
+	result[BlockLabel] = name
+	return result
+}
+
+// serviceLabels adds the service index to the block labels, keeping the assignment of
+// an existing service.
+func (sb *ServiceBlock) serviceLabels(index int, existing map[string]string) map[string]string {
+	result := make(map[string]string, len(sb.labels)+2)
+	for key, value := range sb.labels {
+		result[key] = value
+	}
+	result[IndexLabel] = strconv.Itoa(index)
+	if assigned, found := existing[AssignedLabel]; found {
+		result[AssignedLabel] = assigned
+	}
+	return result
+}
+
+// newBlockName picks a random block name that no existing service uses, either as its
+// block label or as the prefix of its name.
+func newBlockName(ctx context.Context, client Backend) (string, error) {
+	services, err := client.List(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	for attempt := 0; attempt < 10; attempt++ {
+		name := randomString(6)
+		if !blockNameUsed(services, name) {
+			return name, nil
+		}
+	}
+
+	return "", fmt.Errorf("could not find an unused block name")
+}
+
+// blockNameUsed matches services labelled with the block name and unlabelled services
+// named "<name>-<index>", so "api" does not collide with "api-v2".
+func blockNameUsed(services []*pb.Service, name string) bool {
+	for _, service := range services {
+		if service.Labels[BlockLabel] == name {
+			return true
+		}
+
+		serviceName := ParseServiceName(service.Name)
+		separator := strings.LastIndex(serviceName, "-")
+		if separator < 1 || serviceName[:separator] != name {
+			continue
+		}
+		if _, err := strconv.Atoi(serviceName[separator+1:]); err == nil {
+			return true
+		}
+	}
+	return false
+}
 
 func randomString(n int) string {
 	b := make([]rune, n)
@@ -0,0 +1,291 @@
+package cloudrun
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/angelini/sblocks/internal/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func testContext(t *testing.T) context.Context {
+	ctx, err := log.Init(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctx
+}
+
+func testRevision(name string) *Revision {
+	return &Revision{
+		Name:    name,
+		Timeout: time.Minute,
+		Containers: map[string]Container{
+			"app": {Name: "app", Image: "gcr.io/test/app:1"},
+		},
+	}
+}
+
+func TestCreateServiceBlockRollsBackOnFailure(t *testing.T) {
+	ctx := testContext(t)
+	client := NewFakeBackend("project", "region")
+	client.FailNext("Create", "api-2", status.Error(codes.Unavailable, "injected"))
+
+	_, err := CreateNamedServiceBlock(ctx, client, "api", true, 4, map[string]string{EnvironmentLabel: "test"}, testRevision("1"), false)
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected the injected error, found %v", err)
+	}
+
+	services, err := client.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 0 {
+		t.Fatalf("expected every service to be rolled back, found %d", len(services))
+	}
+}
+
+func TestCreateServiceBlockKeepPartial(t *testing.T) {
+	ctx := testContext(t)
+	client := NewFakeBackend("project", "region")
+	client.FailNext("Create", "api-2", status.Error(codes.Unavailable, "injected"))
+
+	_, err := CreateNamedServiceBlock(ctx, client, "api", true, 4, map[string]string{EnvironmentLabel: "test"}, testRevision("1"), true)
+
+	var partialErr *PartialBlockError
+	if !errors.As(err, &partialErr) {
+		t.Fatalf("expected a PartialBlockError, found %v", err)
+	}
+	if partialErr.Size != 4 {
+		t.Fatalf("expected the requested size 4, found %d", partialErr.Size)
+	}
+	if status.Code(partialErr.Err) != codes.Unavailable {
+		t.Fatalf("expected the injected error, found %v", partialErr.Err)
+	}
+
+	services, err := client.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 3 || partialErr.Block.Size() != 3 {
+		t.Fatalf("expected 3 services to be kept, found %d listed and %d in the block", len(services), partialErr.Block.Size())
+	}
+
+	err = partialErr.Block.Resize(ctx, client, partialErr.Size, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if partialErr.Block.Size() != 4 {
+		t.Fatalf("expected resize to resume the block, found %d services", partialErr.Block.Size())
+	}
+}
+
+func TestMigrateLabelsWithoutDescription(t *testing.T) {
+	ctx := testContext(t)
+	client := NewFakeBackend("project", "region")
+	labels := map[string]string{EnvironmentLabel: "test"}
+
+	for _, name := range []string{"api-0", "api-1", "unrelated"} {
+		_, err := client.Create(ctx, name, labels, testRevision("1"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// updates made before the description was sent on every update cleared it
+		client.services[name].service.Description = ""
+	}
+
+	migrated, err := MigrateLabels(ctx, client, "test", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrated) != 2 || migrated["api-0"][BlockLabel] != "api" || migrated["api-1"][IndexLabel] != "1" {
+		t.Fatalf("expected api-0 and api-1 to be migrated into block api, found %v", migrated)
+	}
+
+	blocks, err := LoadServiceBlocks(ctx, client, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blocks["api"] == nil || blocks["api"].Size() != 2 {
+		t.Fatalf("expected the migrated services to load as block api, found %v", blocks)
+	}
+}
+
+func TestResizeCopiesTrafficSplit(t *testing.T) {
+	ctx := testContext(t)
+	client := NewFakeBackend("project", "region")
+
+	block, err := CreateNamedServiceBlock(ctx, client, "api", false, 2, map[string]string{EnvironmentLabel: "test"}, testRevision("1"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = block.StageRevision(ctx, client, testRevision("2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = block.StageRevision(ctx, client, testRevision("3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = block.SetTraffic(ctx, client, map[string]int{"1": 70, "2": 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = block.Resize(ctx, client, 4, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	split, err := block.TrafficSplit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(split) != 2 || split["1"] != 70 || split["2"] != 30 {
+		t.Fatalf("expected the new services to copy the 1=70,2=30 split, found %v", split)
+	}
+
+	latest, err := block.LatestRevision()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.Name != "3" {
+		t.Fatalf("expected revision 3 to stay the latest, found %s", latest.Name)
+	}
+	if !block.HasRevision("3") {
+		t.Fatal("expected revision 3 on every service")
+	}
+}
+
+func TestLatestRevisionKeepsIngress(t *testing.T) {
+	ctx := testContext(t)
+	client := NewFakeBackend("project", "region")
+
+	revision := testRevision("1")
+	revision.Ingress = IngressInternal
+	block, err := CreateNamedServiceBlock(ctx, client, "api", false, 2, map[string]string{EnvironmentLabel: "test"}, revision, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := block.LatestRevision()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.Ingress != IngressInternal {
+		t.Fatalf("expected the latest revision to keep internal ingress, found %q", latest.Ingress)
+	}
+
+	latest.Name = "2"
+	err = block.StageRevision(ctx, client, latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	services, err := client.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, service := range services {
+		if IngressDefinition(service.Ingress) != IngressInternal {
+			t.Fatalf("expected %s to stay internal, found %s", service.Name, IngressDefinition(service.Ingress))
+		}
+	}
+}
+
+func TestResizeCopiesTags(t *testing.T) {
+	ctx := testContext(t)
+	client := NewFakeBackend("project", "region")
+
+	block, err := CreateNamedServiceBlock(ctx, client, "api", false, 2, map[string]string{EnvironmentLabel: "test"}, testRevision("1"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = block.TagRevision(ctx, client, "1", "stable")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = block.Resize(ctx, client, 3, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uris := block.TagURIs("stable"); len(uris) != 3 {
+		t.Fatalf("expected a stable URL on all 3 services, found %v", uris)
+	}
+
+	err = block.CreateRevision(ctx, client, testRevision("2"), "canary")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = block.Resize(ctx, client, 4, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range []string{"stable", "canary"} {
+		if uris := block.TagURIs(tag); len(uris) != 4 {
+			t.Fatalf("expected a %s URL on all 4 services, found %v", tag, uris)
+		}
+	}
+
+	split, err := block.TrafficSplit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(split) != 1 || split["1"] != 100 {
+		t.Fatalf("expected all traffic to stay on revision 1, found %v", split)
+	}
+}
+
+func TestFindDeletable(t *testing.T) {
+	ctx := testContext(t)
+	client := NewFakeBackend("project", "region")
+
+	for _, block := range []struct {
+		name        string
+		environment string
+	}{{"api", "test"}, {"web", "test"}, {"api", "prod"}} {
+		_, err := client.Create(ctx, block.environment+"-"+block.name+"-0", blockLabels(block.name, map[string]string{EnvironmentLabel: block.environment}), testRevision("1"))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// carries the scope's labels but was not created by sblocks
+	_, err := client.Create(ctx, "manual", map[string]string{EnvironmentLabel: "test", BlockLabel: "api"}, testRevision("1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		scope    DeleteScope
+		expected string
+	}{
+		{"block", DeleteScope{Environment: "test", Block: "api"}, "test-api-0"},
+		{"environment", DeleteScope{Environment: "test"}, "test-api-0,test-web-0"},
+		{"block across environments", DeleteScope{Block: "api"}, "prod-api-0,test-api-0"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			services, err := FindDeletable(ctx, client, test.scope)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if found := strings.Join(services, ","); found != test.expected {
+				t.Fatalf("expected %s, found %s", test.expected, found)
+			}
+		})
+	}
+
+	_, err = FindDeletable(ctx, client, DeleteScope{})
+	if err == nil {
+		t.Fatal("expected an empty scope to be refused")
+	}
+}
@@ -6,13 +6,93 @@ import (
 	"time"
 
 	pb "cloud.google.com/go/run/apiv2/runpb"
+	"github.com/angelini/sblocks/internal/maps"
 )
 
+type ConditionState string
+
+const (
+	ConditionPending     ConditionState = "pending"
+	ConditionReconciling ConditionState = "reconciling"
+	ConditionFailed      ConditionState = "failed"
+	ConditionSucceeded   ConditionState = "succeeded"
+	ConditionUnknown     ConditionState = "unknown"
+)
+
+var conditionStates = map[pb.Condition_State]ConditionState{
+	pb.Condition_CONDITION_PENDING:     ConditionPending,
+	pb.Condition_CONDITION_RECONCILING: ConditionReconciling,
+	pb.Condition_CONDITION_FAILED:      ConditionFailed,
+	pb.Condition_CONDITION_SUCCEEDED:   ConditionSucceeded,
+}
+
+type Condition struct {
+	Type           string
+	State          ConditionState
+	Reason         string
+	Message        string
+	LastTransition time.Time
+}
+
+func ConditionDefinition(condition *pb.Condition) Condition {
+	state, found := conditionStates[condition.State]
+	if !found {
+		state = ConditionUnknown
+	}
+
+	reason := ""
+	switch {
+	case condition.GetReason() != pb.Condition_COMMON_REASON_UNDEFINED:
+		reason = condition.GetReason().String()
+	case condition.GetRevisionReason() != pb.Condition_REVISION_REASON_UNDEFINED:
+		reason = condition.GetRevisionReason().String()
+	case condition.GetExecutionReason() != pb.Condition_EXECUTION_REASON_UNDEFINED:
+		reason = condition.GetExecutionReason().String()
+	}
+
+	result := Condition{
+		Type:    condition.Type,
+		State:   state,
+		Reason:  reason,
+		Message: condition.Message,
+	}
+	if condition.LastTransitionTime != nil {
+		result.LastTransition = condition.LastTransitionTime.AsTime()
+	}
+	return result
+}
+
+func conditionDefinitions(conditions ...*pb.Condition) []Condition {
+	result := make([]Condition, 0, len(conditions))
+	for _, condition := range conditions {
+		if condition != nil {
+			result = append(result, ConditionDefinition(condition))
+		}
+	}
+	return result
+}
+
+func (c *Condition) String() string {
+	result := fmt.Sprintf("%s=%s", c.Type, c.State)
+	if c.Reason != "" {
+		result += fmt.Sprintf(" (%s)", c.Reason)
+	}
+	if c.Message != "" {
+		result += ": " + c.Message
+	}
+	if !c.LastTransition.IsZero() {
+		result += fmt.Sprintf(" [%s]", c.LastTransition.Format(time.RFC3339))
+	}
+	return result
+}
+
 type ServiceState struct {
 	isReconciling      bool
 	observedGeneration int64
 	latestRevision     string
 	isReady            bool
+	isFailed           bool
+	conditions         []Condition
 }
 
 func NewServiceState() ServiceState {
@@ -24,21 +104,22 @@ func NewServiceState() ServiceState {
 	}
 }
 
-func ErrorServiceState() ServiceState {
-	return ServiceState{
-		isReconciling:      false,
-		observedGeneration: -1,
-		latestRevision:     "",
-		isReady:            false,
+func GetServiceState(service *pb.Service) ServiceState {
+	terminal := service.GetTerminalCondition()
+
+	// LatestReadyRevision is a full resource name, keep only the revision ID
+	latestRevision := service.LatestReadyRevision
+	if latestRevision != "" {
+		latestRevision = ParseRevisionName(latestRevision)
 	}
-}
 
-func GetServiceState(service *pb.Service) ServiceState {
 	return ServiceState{
 		isReconciling:      service.Reconciling,
 		observedGeneration: service.ObservedGeneration,
-		latestRevision:     service.LatestReadyRevision,
-		isReady:            service.TerminalCondition.Type == "Ready",
+		latestRevision:     latestRevision,
+		isReady:            terminal.GetType() == "Ready" && terminal.GetState() == pb.Condition_CONDITION_SUCCEEDED,
+		isFailed:           terminal.GetState() == pb.Condition_CONDITION_FAILED,
+		conditions:         conditionDefinitions(append([]*pb.Condition{terminal}, service.Conditions...)...),
 	}
 }
 
@@ -48,6 +129,10 @@ func (s *ServiceState) String() string {
 		result = "READY"
 	}
 
+	if s.isFailed {
+		result = "FAILED"
+	}
+
 	if s.isReconciling {
 		result += "(*)"
 	}
@@ -55,19 +140,46 @@ func (s *ServiceState) String() string {
 	return result
 }
 
+// problems returns the conditions that have not succeeded, which explain why a service
+// or revision is not ready.
+func problems(conditions []Condition) []Condition {
+	result := []Condition{}
+	for _, condition := range conditions {
+		if condition.State != ConditionSucceeded {
+			result = append(result, condition)
+		}
+	}
+	return result
+}
+
 type RevisionState struct {
 	isReconciling      bool
 	observedGeneration int64
 	isDeleted          bool
+	isReady            bool
+	isFailed           bool
 	traffic            int
+	conditions         []Condition
 }
 
 func GetRevisionState(revision *pb.Revision, traffic int) RevisionState {
+	isReady := false
+	isFailed := false
+	for _, condition := range revision.Conditions {
+		if condition.Type == "Ready" {
+			isReady = condition.State == pb.Condition_CONDITION_SUCCEEDED
+			isFailed = condition.State == pb.Condition_CONDITION_FAILED
+		}
+	}
+
 	return RevisionState{
 		isReconciling:      revision.Reconciling,
 		observedGeneration: revision.ObservedGeneration,
 		isDeleted:          revision.DeleteTime != nil,
+		isReady:            isReady,
+		isFailed:           isFailed,
 		traffic:            traffic,
+		conditions:         conditionDefinitions(revision.Conditions...),
 	}
 }
 
@@ -86,6 +198,10 @@ func (r *RevisionState) String() string {
 		result = fmt.Sprintf("ACTIVE (%d)", r.traffic)
 	}
 
+	if r.isFailed {
+		result = "FAILED"
+	}
+
 	if r.isDeleted {
 		result = "DELETED"
 	}
@@ -97,11 +213,46 @@ func (r *RevisionState) String() string {
 	return result
 }
 
+type SecretRef struct {
+	Secret  string
+	Version string
+}
+
+func (s *SecretRef) version() string {
+	if s.Version == "" {
+		return "latest"
+	}
+	return s.Version
+}
+
+func (s *SecretRef) String() string {
+	return fmt.Sprintf("%s@%s", s.Secret, s.version())
+}
+
+type Port struct {
+	Name   string
+	Number uint32
+}
+
+func (p *Port) String() string {
+	if p.Name == "" {
+		return fmt.Sprintf("%d", p.Number)
+	}
+	return fmt.Sprintf("%s:%d", p.Name, p.Number)
+}
+
 type Container struct {
-	Name    string
-	Image   string
-	Command string
-	Args    []string
+	Name          string
+	Image         string
+	Command       string
+	Args          []string
+	Env           map[string]string
+	SecretEnv     map[string]SecretRef
+	Resources     Resources
+	Ports         []Port
+	VolumeMounts  []VolumeMount
+	StartupProbe  *Probe
+	LivenessProbe *Probe
 }
 
 func ContainerDefinition(container *pb.Container) Container {
@@ -110,64 +261,270 @@ func ContainerDefinition(container *pb.Container) Container {
 		command = container.Command[0]
 	}
 
+	env := make(map[string]string)
+	secretEnv := make(map[string]SecretRef)
+	for _, variable := range container.Env {
+		if ref := variable.GetValueSource().GetSecretKeyRef(); ref != nil {
+			secretEnv[variable.Name] = SecretRef{
+				Secret:  ref.Secret,
+				Version: ref.Version,
+			}
+		} else {
+			env[variable.Name] = variable.GetValue()
+		}
+	}
+
+	resources := Resources{
+		CPU:                container.GetResources().GetLimits()["cpu"],
+		Memory:             container.GetResources().GetLimits()["memory"],
+		CPUAlwaysAllocated: container.Resources != nil && !container.Resources.CpuIdle,
+	}
+
+	ports := make([]Port, 0, len(container.Ports))
+	for _, port := range container.Ports {
+		ports = append(ports, Port{
+			Name:   port.Name,
+			Number: uint32(port.ContainerPort),
+		})
+	}
+
+	mounts := make([]VolumeMount, 0, len(container.VolumeMounts))
+	for _, mount := range container.VolumeMounts {
+		mounts = append(mounts, VolumeMount{
+			Volume: mount.Name,
+			Path:   mount.MountPath,
+		})
+	}
+
 	return Container{
-		Name:    container.Name,
-		Image:   container.Image,
-		Command: command,
-		Args:    container.Args,
+		Name:          container.Name,
+		Image:         container.Image,
+		Command:       command,
+		Args:          container.Args,
+		Env:           env,
+		SecretEnv:     secretEnv,
+		Resources:     resources,
+		Ports:         ports,
+		VolumeMounts:  mounts,
+		StartupProbe:  ProbeDefinition(container.StartupProbe),
+		LivenessProbe: ProbeDefinition(container.LivenessProbe),
 	}
 }
 
+func (c *Container) Validate(maxConcurrency uint32, volumes map[string]Volume) error {
+	err := c.Resources.Validate(maxConcurrency)
+	if err != nil {
+		return err
+	}
+
+	for _, mount := range c.VolumeMounts {
+		err = mount.validate(volumes)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(c.Ports) > 1 {
+		return fmt.Errorf("only a single port can be declared, found %d", len(c.Ports))
+	}
+
+	for _, port := range c.Ports {
+		if port.Name != "" && port.Name != "http1" && port.Name != "h2c" {
+			return fmt.Errorf("port name %q must be http1 or h2c", port.Name)
+		}
+		if port.Number == 0 || port.Number > 65535 {
+			return fmt.Errorf("invalid port number %d", port.Number)
+		}
+	}
+
+	if c.StartupProbe != nil {
+		err = c.StartupProbe.validate(240*time.Second, HTTPProbe, TCPProbe, GRPCProbe)
+		if err != nil {
+			return fmt.Errorf("startup probe: %w", err)
+		}
+	}
+
+	if c.LivenessProbe != nil {
+		err = c.LivenessProbe.validate(3600*time.Second, HTTPProbe, GRPCProbe)
+		if err != nil {
+			return fmt.Errorf("liveness probe: %w", err)
+		}
+	}
+
+	return nil
+}
+
 type Revision struct {
-	Name           string
-	MinScale       uint32
-	MaxScale       uint32
-	MaxConcurrency uint32
-	Timeout        time.Duration
-	Containers     map[string]Container
+	Name            string
+	MinScale        uint32
+	MaxScale        uint32
+	MaxConcurrency  uint32
+	Timeout         time.Duration
+	StartupCPUBoost bool
+	Containers      map[string]Container
+	Volumes         map[string]Volume
+	ServiceAccount  string
+	VPCAccess       *VPCAccess
+	Ingress         Ingress
+}
+
+func (r *Revision) Validate() error {
+	err := r.Ingress.validate()
+	if err != nil {
+		return err
+	}
+
+	if r.VPCAccess != nil {
+		err = r.VPCAccess.validate()
+		if err != nil {
+			return fmt.Errorf("vpc access: %w", err)
+		}
+	}
+
+	for _, volume := range maps.SortedValues(r.Volumes) {
+		err := volume.validate()
+		if err != nil {
+			return fmt.Errorf("volume %s: %w", volume.Name, err)
+		}
+	}
+
+	for _, container := range maps.SortedValues(r.Containers) {
+		err := container.Validate(r.MaxConcurrency, r.Volumes)
+		if err != nil {
+			return fmt.Errorf("container %s: %w", container.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Cloud Run fills these in for revisions that leave them out
+const (
+	defaultMaxConcurrency = 80
+	defaultTimeout        = 5 * time.Minute
+	defaultPortName       = "http1"
+	defaultPort           = 8080
+	defaultProbeTimeout   = 240 * time.Second
+)
+
+// withDefaults returns a copy of the revision with the values Cloud Run fills in for
+// unset fields, so a revision compares equal to what Cloud Run reads back.
+func (r *Revision) withDefaults() *Revision {
+	result := *r
+	if result.MaxConcurrency == 0 {
+		result.MaxConcurrency = defaultMaxConcurrency
+	}
+	if result.Timeout == 0 {
+		result.Timeout = defaultTimeout
+	}
+
+	result.Containers = make(map[string]Container, len(r.Containers))
+	for name, container := range r.Containers {
+		container.Resources.CPU, container.Resources.Memory = container.Resources.limits()
+
+		if len(container.Ports) == 0 && len(r.Containers) == 1 {
+			container.Ports = []Port{{Name: defaultPortName, Number: defaultPort}}
+		}
+
+		if container.StartupProbe == nil && len(container.Ports) > 0 {
+			container.StartupProbe = &Probe{
+				Type:             TCPProbe,
+				Port:             container.Ports[0].Number,
+				Timeout:          defaultProbeTimeout,
+				Period:           defaultProbeTimeout,
+				FailureThreshold: 1,
+			}
+		}
+
+		result.Containers[name] = container
+	}
+
+	return &result
 }
 
 func RevisionDefinition(revision *pb.Revision) Revision {
 	containers := make(map[string]Container, len(revision.Containers))
+	startupCPUBoost := false
 	for _, container := range revision.Containers {
 		containers[container.Name] = ContainerDefinition(container)
+		startupCPUBoost = startupCPUBoost || container.GetResources().GetStartupCpuBoost()
 	}
 
+	volumes := make(map[string]Volume, len(revision.Volumes))
+	for _, volume := range revision.Volumes {
+		volumes[volume.Name] = VolumeDefinition(volume)
+	}
+
+	serviceName := strings.SplitN(revision.Name, "/", 8)[5]
+
 	return Revision{
-		Name:           ParseRevisionName(revision.Name),
-		MinScale:       uint32(revision.Scaling.MinInstanceCount),
-		MaxScale:       uint32(revision.Scaling.MaxInstanceCount),
-		MaxConcurrency: uint32(revision.MaxInstanceRequestConcurrency),
-		Timeout:        revision.Timeout.AsDuration(),
-		Containers:     containers,
+		Name:            strings.TrimPrefix(ParseRevisionName(revision.Name), serviceName+"-"),
+		MinScale:        uint32(revision.GetScaling().GetMinInstanceCount()),
+		MaxScale:        uint32(revision.GetScaling().GetMaxInstanceCount()),
+		MaxConcurrency:  uint32(revision.MaxInstanceRequestConcurrency),
+		Timeout:         revision.Timeout.AsDuration(),
+		StartupCPUBoost: startupCPUBoost,
+		Containers:      containers,
+		Volumes:         volumes,
+		ServiceAccount:  revision.ServiceAccount,
+		VPCAccess:       VPCAccessDefinition(revision.VpcAccess),
 	}
 }
 
+func RevisionID(serviceName, revisionName string) string {
+	return fmt.Sprintf("%s-%s", serviceName, revisionName)
+}
+
 func ParseServiceName(resource string) string {
 	return strings.SplitN(resource, "/", 6)[5]
 }
 
+// ParseLocation returns the location of a service, revision or operation resource name.
+func ParseLocation(resource string) string {
+	return strings.SplitN(resource, "/", 5)[3]
+}
+
 func ParseRevisionName(resource string) string {
 	return strings.SplitN(resource, "/", 8)[7]
 }
 
+// TrafficTarget routes a percentage of traffic to a revision, or to the latest ready
+// revision when Latest is set. A tagged target gets its own URL, even at 0 percent.
+type TrafficTarget struct {
+	Revision string
+	Latest   bool
+	Percent  int32
+	Tag      string
+}
+
+type TrafficTag struct {
+	Revision string
+	URI      string
+}
+
 type TrafficStatus struct {
 	latest    bool
 	revisions map[string]int32
+	tags      map[string]TrafficTag
 }
 
 func NewTrafficStatus(statuses []*pb.TrafficTargetStatus) *TrafficStatus {
-	if len(statuses) == 1 && statuses[0].Type == pb.TrafficTargetAllocationType_TRAFFIC_TARGET_ALLOCATION_TYPE_LATEST {
-		return &TrafficStatus{latest: true}
+	result := &TrafficStatus{
+		revisions: make(map[string]int32, len(statuses)),
+		tags:      make(map[string]TrafficTag),
 	}
 
-	revisions := make(map[string]int32, len(statuses))
 	for _, status := range statuses {
-		revisions[status.Revision] = status.Percent
-	}
+		if status.Tag != "" && status.Revision != "" {
+			result.tags[status.Tag] = TrafficTag{Revision: status.Revision, URI: status.Uri}
+		}
 
-	return &TrafficStatus{
-		latest:    false,
-		revisions: revisions,
+		if status.Type == pb.TrafficTargetAllocationType_TRAFFIC_TARGET_ALLOCATION_TYPE_LATEST && status.Percent == 100 {
+			result.latest = true
+			continue
+		}
+		result.revisions[status.Revision] += status.Percent
 	}
+
+	return result
 }
@@ -0,0 +1,254 @@
+package cloudrun
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/angelini/sblocks/internal/maps"
+)
+
+type DriftField struct {
+	Field   string
+	Desired string
+	Actual  string
+}
+
+func (d *DriftField) String() string {
+	return fmt.Sprintf("%s: desired %q, actual %q", d.Field, d.Desired, d.Actual)
+}
+
+type ServiceDrift struct {
+	Service string
+	Fields  []DriftField
+}
+
+type driftDiff struct {
+	fields []DriftField
+}
+
+func (d *driftDiff) compare(field, desired, actual string) {
+	if desired != actual {
+		d.fields = append(d.fields, DriftField{Field: field, Desired: desired, Actual: actual})
+	}
+}
+
+func formatList(values []string) string {
+	return strings.Join(values, " ")
+}
+
+func formatProbe(probe *Probe) string {
+	if probe == nil {
+		return ""
+	}
+	return probe.String()
+}
+
+func (d *driftDiff) compareContainer(prefix string, desired, actual Container) {
+	d.compare(prefix+".image", desired.Image, actual.Image)
+	d.compare(prefix+".command", desired.Command, actual.Command)
+	d.compare(prefix+".args", formatList(desired.Args), formatList(actual.Args))
+	d.compare(prefix+".env", FormatLabels(desired.Env), FormatLabels(actual.Env))
+	secretEnv := func(container Container) string {
+		entries := []string{}
+		for _, key := range maps.SortedKeys(container.SecretEnv) {
+			ref := container.SecretEnv[key]
+			entries = append(entries, fmt.Sprintf("%s=%s", key, ref.String()))
+		}
+		return strings.Join(entries, ",")
+	}
+	d.compare(prefix+".secret-env", secretEnv(desired), secretEnv(actual))
+	if desired.Resources.normalized() != actual.Resources.normalized() {
+		d.fields = append(d.fields, DriftField{Field: prefix + ".resources", Desired: desired.Resources.String(), Actual: actual.Resources.String()})
+	}
+
+	ports := func(container Container) string {
+		entries := []string{}
+		for _, port := range container.Ports {
+			entries = append(entries, port.String())
+		}
+		return formatList(entries)
+	}
+	d.compare(prefix+".ports", ports(desired), ports(actual))
+
+	mounts := func(container Container) string {
+		entries := []string{}
+		for _, mount := range container.VolumeMounts {
+			entries = append(entries, fmt.Sprintf("%s:%s", mount.Volume, mount.Path))
+		}
+		return formatList(entries)
+	}
+	d.compare(prefix+".volume-mounts", mounts(desired), mounts(actual))
+
+	d.compare(prefix+".startup-probe", formatProbe(desired.StartupProbe), formatProbe(actual.StartupProbe))
+	d.compare(prefix+".liveness-probe", formatProbe(desired.LivenessProbe), formatProbe(actual.LivenessProbe))
+}
+
+func (d *driftDiff) compareRevision(desired, actual *Revision) {
+	desired, actual = desired.withDefaults(), actual.withDefaults()
+
+	d.compare("revision", desired.Name, actual.Name)
+	d.compare("min-scale", fmt.Sprint(desired.MinScale), fmt.Sprint(actual.MinScale))
+	d.compare("max-scale", fmt.Sprint(desired.MaxScale), fmt.Sprint(actual.MaxScale))
+	d.compare("max-concurrency", fmt.Sprint(desired.MaxConcurrency), fmt.Sprint(actual.MaxConcurrency))
+	d.compare("timeout", desired.Timeout.String(), actual.Timeout.String())
+	d.compare("startup-cpu-boost", fmt.Sprint(desired.StartupCPUBoost), fmt.Sprint(actual.StartupCPUBoost))
+	d.compare("service-account", desired.ServiceAccount, actual.ServiceAccount)
+
+	vpc := func(access *VPCAccess) string {
+		if access == nil {
+			return ""
+		}
+		return access.String()
+	}
+	d.compare("vpc", vpc(desired.VPCAccess), vpc(actual.VPCAccess))
+
+	volumes := func(revision *Revision) string {
+		entries := []string{}
+		for _, volume := range maps.SortedValues(revision.Volumes) {
+			entries = append(entries, fmt.Sprintf("%s%s", volume.String(), FormatLabels(volume.Items)))
+		}
+		return formatList(entries)
+	}
+	d.compare("volumes", volumes(desired), volumes(actual))
+
+	names := make(map[string]bool)
+	for name := range desired.Containers {
+		names[name] = true
+	}
+	for name := range actual.Containers {
+		names[name] = true
+	}
+
+	for _, name := range maps.SortedKeys(names) {
+		prefix := "container." + name
+		desiredContainer, inDesired := desired.Containers[name]
+		actualContainer, inActual := actual.Containers[name]
+
+		switch {
+		case !inActual:
+			d.compare(prefix, "present", "missing")
+		case !inDesired:
+			d.compare(prefix, "missing", "present")
+		default:
+			d.compareContainer(prefix, desiredContainer, actualContainer)
+		}
+	}
+}
+
+// DiffRevision lists the fields of actual that differ from desired.
+func DiffRevision(desired, actual *Revision) []DriftField {
+	diff := driftDiff{}
+	diff.compareRevision(desired, actual)
+	return diff.fields
+}
+
+// DetectDrift compares every service of the block against the desired revision, the
+// block's labels, the desired public access and the revision's ingress. Services that
+// match are left out of the result.
+func (sb *ServiceBlock) DetectDrift(ctx context.Context, client Backend, desired *Revision, public bool) ([]ServiceDrift, error) {
+	ingress := desired.Ingress
+	if ingress == "" {
+		ingress = IngressAll
+	}
+
+	results := []ServiceDrift{}
+	for _, service := range maps.SortedValues(sb.services) {
+		diff := driftDiff{}
+
+		var actual *Revision
+		for _, instance := range service.revisions {
+			if RevisionID(service.name, instance.definition.Name) == service.state.latestRevision {
+				actual = instance.definition
+			}
+		}
+
+		if actual == nil {
+			diff.compare("revision", desired.Name, "")
+		} else {
+			diff.compareRevision(desired, actual)
+		}
+
+		index := service.index
+		if index < 0 {
+			// the index label itself drifted, fall back to the "<block>-<index>" name
+			index, _ = strconv.Atoi(strings.TrimPrefix(service.name, sb.name+"-"))
+		}
+		diff.compare("labels", FormatLabels(sb.serviceLabels(index, service.labels)), FormatLabels(service.labels))
+		diff.compare("ingress", string(ingress), string(service.ingress))
+
+		policy, err := client.GetIamPolicy(ctx, service.name)
+		if err != nil {
+			return nil, err
+		}
+		diff.compare("public", fmt.Sprint(public), fmt.Sprint(isPublicPolicy(policy)))
+
+		if len(diff.fields) > 0 {
+			results = append(results, ServiceDrift{Service: service.name, Fields: diff.fields})
+		}
+	}
+
+	return results, nil
+}
+
+// DesiredRevision returns the newest revision deployed to every service in the block.
+// Revisions created outside of sblocks, such as console edits, only exist on a single
+// service and are not chosen, except on single service blocks where the recorded spec
+// has to be used instead.
+func (sb *ServiceBlock) DesiredRevision() (*Revision, error) {
+	services := maps.SortedValues(sb.services)
+	if len(services) == 0 {
+		return nil, fmt.Errorf("block %s has no services", sb.name)
+	}
+
+	candidates := maps.SortedValues(services[0].revisions)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].created.After(candidates[j].created)
+	})
+
+	for _, candidate := range candidates {
+		if candidate.state.isDeleted || !sb.HasRevision(candidate.definition.Name) {
+			continue
+		}
+
+		revision := *candidate.definition
+		revision.Ingress = sb.majorityIngress()
+		return &revision, nil
+	}
+
+	return nil, fmt.Errorf("block %s has no revision shared by every service", sb.name)
+}
+
+// majorityIngress returns the ingress shared by most services, so that a single edited
+// service is the one reported as drifted. Ties go to the first service by name.
+func (sb *ServiceBlock) majorityIngress() Ingress {
+	counts := make(map[Ingress]int)
+	var result Ingress
+	for _, service := range maps.SortedValues(sb.services) {
+		counts[service.ingress]++
+		if counts[service.ingress] > counts[result] {
+			result = service.ingress
+		}
+	}
+	return result
+}
+
+// ExpectedPublic infers whether the block is meant to be public from the majority of its
+// services. A tie counts as public, as access is more often revoked by hand than granted.
+func (sb *ServiceBlock) ExpectedPublic(ctx context.Context, client Backend) (bool, error) {
+	public := 0
+	for _, service := range maps.SortedValues(sb.services) {
+		policy, err := client.GetIamPolicy(ctx, service.name)
+		if err != nil {
+			return false, err
+		}
+
+		if isPublicPolicy(policy) {
+			public++
+		}
+	}
+
+	return public > 0 && public*2 >= len(sb.services), nil
+}
@@ -0,0 +1,122 @@
+package cloudrun
+
+import (
+	"testing"
+
+	iampb "cloud.google.com/go/iam/apiv1/iampb"
+)
+
+func driftedServices(drifts []ServiceDrift) map[string][]string {
+	result := make(map[string][]string, len(drifts))
+	for _, drift := range drifts {
+		for _, field := range drift.Fields {
+			result[drift.Service] = append(result[drift.Service], field.Field)
+		}
+	}
+	return result
+}
+
+func TestDetectDriftReportsTheEditedService(t *testing.T) {
+	ctx := testContext(t)
+	client := NewFakeBackend("project", "region")
+
+	block, err := CreateNamedServiceBlock(ctx, client, "api", true, 3, map[string]string{EnvironmentLabel: "test"}, testRevision("1"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = client.UpdateIamPolicy(ctx, "api-1", func(policy *iampb.Policy) bool {
+		return editInvokers(policy, nil, []string{AllUsers})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edited := testRevision("console")
+	edited.Ingress = IngressInternal
+	err = client.Update(ctx, "api-2", block.serviceLabels(2, nil), edited, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = block.Refresh(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	public, err := block.ExpectedPublic(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !public {
+		t.Fatal("expected the majority of services to keep the block public")
+	}
+
+	desired, err := block.DesiredRevision()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desired.Ingress != IngressAll {
+		t.Fatalf("expected the majority ingress all, found %s", desired.Ingress)
+	}
+
+	drifts, err := block.DetectDrift(ctx, client, desired, public)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	drifted := driftedServices(drifts)
+	if len(drifted) != 2 || len(drifted["api-1"]) != 1 || drifted["api-1"][0] != "public" {
+		t.Fatalf("expected api-1 to drift on public access and api-2 on ingress, found %v", drifted)
+	}
+	for _, field := range drifted["api-2"] {
+		if field == "ingress" {
+			return
+		}
+	}
+	t.Fatalf("expected api-2 to drift on ingress, found %v", drifted)
+}
+
+func TestDetectDriftAgainstRecordedRevision(t *testing.T) {
+	ctx := testContext(t)
+	client := NewFakeBackend("project", "region")
+
+	block, err := CreateNamedServiceBlock(ctx, client, "api", false, 1, map[string]string{EnvironmentLabel: "test"}, testRevision("1"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a console edit on a single service block becomes the revision shared by every service
+	edited := testRevision("console")
+	edited.MaxScale = 3
+	err = client.Update(ctx, "api-0", block.serviceLabels(0, nil), edited, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = block.Refresh(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inferred, err := block.DesiredRevision()
+	if err != nil {
+		t.Fatal(err)
+	}
+	drifts, err := block.DetectDrift(ctx, client, inferred, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drifts) != 0 {
+		t.Fatalf("expected the inferred revision to hide the edit, found %v", driftedServices(drifts))
+	}
+
+	drifts, err = block.DetectDrift(ctx, client, testRevision("1"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	drifted := driftedServices(drifts)
+	if len(drifted["api-0"]) != 2 {
+		t.Fatalf("expected the recorded revision to report the name and max-scale, found %v", drifted)
+	}
+}
@@ -0,0 +1,486 @@
+package cloudrun
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	iampb "cloud.google.com/go/iam/apiv1/iampb"
+	pb "cloud.google.com/go/run/apiv2/runpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type fakeService struct {
+	service   *pb.Service
+	revisions []*pb.Revision
+	policy    *iampb.Policy
+	etag      int
+	polls     int
+}
+
+// FakeBackend is an in-memory Cloud Run used to test block logic without a GCP project.
+// Every mutation leaves the service reconciling for ReconcilePolls calls to List before it becomes ready.
+type FakeBackend struct {
+	Parent         string
+	ReconcilePolls int
+	MaxConcurrency int
+
+	mu       sync.Mutex
+	services map[string]*fakeService
+	failures []injectedFailure
+}
+
+type injectedFailure struct {
+	method      string
+	serviceName string
+	err         error
+}
+
+var _ Backend = (*FakeBackend)(nil)
+
+func NewFakeBackend(project, location string) *FakeBackend {
+	return &FakeBackend{
+		Parent:   fmt.Sprintf("projects/%s/locations/%s", project, location),
+		services: make(map[string]*fakeService),
+	}
+}
+
+// FailNext makes the next call to method (e.g. "Create") for serviceName return err.
+// An empty serviceName matches a call for any service.
+func (f *FakeBackend) FailNext(method, serviceName string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.failures = append(f.failures, injectedFailure{
+		method:      method,
+		serviceName: serviceName,
+		err:         err,
+	})
+}
+
+func (f *FakeBackend) injected(method, serviceName string) error {
+	for idx, failure := range f.failures {
+		if failure.method != method || (failure.serviceName != "" && failure.serviceName != serviceName) {
+			continue
+		}
+
+		f.failures = append(f.failures[:idx], f.failures[idx+1:]...)
+		return failure.err
+	}
+
+	return nil
+}
+
+// PendingOperations is always empty as every fake mutation completes before returning.
+func (f *FakeBackend) PendingOperations(ctx context.Context) ([]Operation, error) {
+	return nil, nil
+}
+
+func (f *FakeBackend) WaitOperation(ctx context.Context, operation Operation) error {
+	return status.Errorf(codes.NotFound, "operation %s not found", operation.Name)
+}
+
+func (f *FakeBackend) Concurrency() int {
+	return f.MaxConcurrency
+}
+
+func (f *FakeBackend) serviceResource(serviceName string) string {
+	return fmt.Sprintf("%s/services/%s", f.Parent, serviceName)
+}
+
+func (f *FakeBackend) lookup(serviceName string) (*fakeService, error) {
+	fake, found := f.services[serviceName]
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "service %s not found", f.serviceResource(serviceName))
+	}
+	return fake, nil
+}
+
+func (f *FakeBackend) deploy(fake *fakeService, template *pb.RevisionTemplate) error {
+	service := fake.service
+	generation := service.Generation + 1
+
+	revisionName := template.Revision
+	if revisionName == "" {
+		revisionName = fmt.Sprintf("%s-%05d", ParseServiceName(service.Name), generation)
+	}
+
+	for _, existing := range fake.revisions {
+		if ParseRevisionName(existing.Name) == revisionName {
+			return status.Errorf(codes.AlreadyExists, "revision %s already exists", revisionName)
+		}
+	}
+
+	scaling := &pb.RevisionScaling{}
+	if template.Scaling != nil {
+		scaling = proto.Clone(template.Scaling).(*pb.RevisionScaling)
+	}
+
+	revision := &pb.Revision{
+		Name:                          fmt.Sprintf("%s/revisions/%s", service.Name, revisionName),
+		Uid:                           revisionName,
+		Generation:                    1,
+		Labels:                        template.Labels,
+		CreateTime:                    timestamppb.Now(),
+		Service:                       ParseServiceName(service.Name),
+		Scaling:                       scaling,
+		VpcAccess:                     template.VpcAccess,
+		ServiceAccount:                template.ServiceAccount,
+		Timeout:                       template.Timeout,
+		MaxInstanceRequestConcurrency: template.MaxInstanceRequestConcurrency,
+		Containers:                    template.Containers,
+		Volumes:                       template.Volumes,
+		Reconciling:                   true,
+	}
+
+	// Cloud Run lists revisions from newest to oldest
+	fake.revisions = append([]*pb.Revision{revision}, fake.revisions...)
+
+	service.Template = template
+	service.LatestCreatedRevision = revision.Name
+	f.mutate(fake)
+
+	return nil
+}
+
+func (f *FakeBackend) mutate(fake *fakeService) {
+	service := fake.service
+	service.Generation += 1
+	service.Reconciling = true
+	service.UpdateTime = timestamppb.Now()
+	service.TerminalCondition = &pb.Condition{
+		Type:  "Ready",
+		State: pb.Condition_CONDITION_RECONCILING,
+	}
+
+	fake.polls = f.ReconcilePolls
+	if fake.polls <= 0 {
+		f.reconcile(fake)
+	}
+}
+
+func (f *FakeBackend) reconcile(fake *fakeService) {
+	service := fake.service
+	service.Reconciling = false
+	service.ObservedGeneration = service.Generation
+	service.LatestReadyRevision = service.LatestCreatedRevision
+	service.TerminalCondition = &pb.Condition{
+		Type:               "Ready",
+		State:              pb.Condition_CONDITION_SUCCEEDED,
+		LastTransitionTime: timestamppb.Now(),
+	}
+
+	if len(service.Traffic) == 0 {
+		service.TrafficStatuses = []*pb.TrafficTargetStatus{
+			{Type: pb.TrafficTargetAllocationType_TRAFFIC_TARGET_ALLOCATION_TYPE_LATEST, Percent: 100},
+		}
+	} else {
+		statuses := make([]*pb.TrafficTargetStatus, 0, len(service.Traffic))
+		for _, target := range service.Traffic {
+			revision := target.Revision
+			if target.Type == pb.TrafficTargetAllocationType_TRAFFIC_TARGET_ALLOCATION_TYPE_LATEST {
+				revision = ParseRevisionName(service.LatestReadyRevision)
+			}
+
+			var uri string
+			if target.Tag != "" {
+				uri = fmt.Sprintf("https://%s---%s.fake.run.app", target.Tag, ParseServiceName(service.Name))
+			}
+
+			statuses = append(statuses, &pb.TrafficTargetStatus{
+				Type:     target.Type,
+				Revision: revision,
+				Percent:  target.Percent,
+				Tag:      target.Tag,
+				Uri:      uri,
+			})
+		}
+		service.TrafficStatuses = statuses
+	}
+
+	for _, revision := range fake.revisions {
+		if !revision.Reconciling {
+			continue
+		}
+
+		revision.Reconciling = false
+		revision.ObservedGeneration = revision.Generation
+		revision.Conditions = []*pb.Condition{
+			{Type: "Ready", State: pb.Condition_CONDITION_SUCCEEDED, LastTransitionTime: timestamppb.Now()},
+		}
+	}
+}
+
+func (f *FakeBackend) poll(fake *fakeService) {
+	if !fake.service.Reconciling {
+		return
+	}
+
+	fake.polls -= 1
+	if fake.polls <= 0 {
+		f.reconcile(fake)
+	}
+}
+
+func (f *FakeBackend) Create(ctx context.Context, name string, labels map[string]string, revision *Revision) (*pb.Service, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.injected("Create", name); err != nil {
+		return nil, err
+	}
+
+	if _, found := f.services[name]; found {
+		return nil, status.Errorf(codes.AlreadyExists, "service %s already exists", f.serviceResource(name))
+	}
+
+	fake := &fakeService{
+		service: &pb.Service{
+			Name:        f.serviceResource(name),
+			Uid:         name,
+			Description: managedDescription,
+			Labels:      labels,
+			CreateTime:  timestamppb.Now(),
+			Ingress:     asPbIngress(revision.Ingress),
+			Uri:         fmt.Sprintf("https://%s.fake.run.app", name),
+		},
+		policy: &iampb.Policy{},
+	}
+
+	// like Cloud Run, fill in the defaults of the fields the revision leaves out
+	err := f.deploy(fake, asPbRevisionTemplate(name, labels, revision.withDefaults()))
+	if err != nil {
+		return nil, err
+	}
+
+	f.services[name] = fake
+	return proto.Clone(fake.service).(*pb.Service), nil
+}
+
+func (f *FakeBackend) List(ctx context.Context) ([]*pb.Service, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.injected("List", ""); err != nil {
+		return nil, err
+	}
+
+	services := make([]*pb.Service, 0, len(f.services))
+	for _, fake := range f.services {
+		f.poll(fake)
+		services = append(services, proto.Clone(fake.service).(*pb.Service))
+	}
+
+	return services, nil
+}
+
+func (f *FakeBackend) ListRevisions(ctx context.Context, serviceName string) ([]*pb.Revision, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.injected("ListRevisions", serviceName); err != nil {
+		return nil, err
+	}
+
+	fake, err := f.lookup(serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := make([]*pb.Revision, 0, len(fake.revisions))
+	for _, revision := range fake.revisions {
+		revisions = append(revisions, proto.Clone(revision).(*pb.Revision))
+	}
+
+	return revisions, nil
+}
+
+func (f *FakeBackend) GetIamPolicy(ctx context.Context, serviceName string) (*iampb.Policy, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.injected("GetIamPolicy", serviceName); err != nil {
+		return nil, err
+	}
+
+	fake, err := f.lookup(serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	return proto.Clone(fake.policy).(*iampb.Policy), nil
+}
+
+func (f *FakeBackend) UpdateIamPolicy(ctx context.Context, serviceName string, update func(*iampb.Policy) bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.injected("SetIamPolicy", serviceName); err != nil {
+		return err
+	}
+
+	fake, err := f.lookup(serviceName)
+	if err != nil {
+		return err
+	}
+
+	policy := proto.Clone(fake.policy).(*iampb.Policy)
+	if update(policy) {
+		fake.etag++
+		policy.Etag = []byte(fmt.Sprint(fake.etag))
+		fake.policy = policy
+	}
+	return nil
+}
+
+func (f *FakeBackend) Update(ctx context.Context, serviceName string, labels map[string]string, revision *Revision, traffic []TrafficTarget) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.injected("Update", serviceName); err != nil {
+		return err
+	}
+
+	fake, err := f.lookup(serviceName)
+	if err != nil {
+		return err
+	}
+
+	template := asPbRevisionTemplate(serviceName, labels, revision.withDefaults())
+	err = checkTargets(fake, traffic, template.Revision)
+	if err != nil {
+		return err
+	}
+
+	// UpdateService replaces the whole service, so fields that Client.Update does not send
+	// are cleared
+	previous := proto.Clone(fake.service).(*pb.Service)
+	fake.service.Description = managedDescription
+	fake.service.Annotations = nil
+	fake.service.Labels = labels
+	fake.service.Ingress = asPbIngress(revision.Ingress)
+	fake.service.Traffic = asPbTraffic(traffic)
+
+	err = f.deploy(fake, template)
+	if err != nil {
+		fake.service = previous
+		return err
+	}
+	return nil
+}
+
+func (f *FakeBackend) SetTraffic(ctx context.Context, serviceName string, targets []TrafficTarget) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.injected("SetTraffic", serviceName); err != nil {
+		return err
+	}
+
+	fake, err := f.lookup(serviceName)
+	if err != nil {
+		return err
+	}
+
+	err = checkTargets(fake, targets, "")
+	if err != nil {
+		return err
+	}
+
+	fake.service.Traffic = asPbTraffic(targets)
+	f.mutate(fake)
+	return nil
+}
+
+// checkTargets fails like Cloud Run when a target names a revision that does not exist,
+// other than the one being created by the same request.
+func checkTargets(fake *fakeService, targets []TrafficTarget, created string) error {
+	for _, target := range targets {
+		if target.Latest || target.Revision == created {
+			continue
+		}
+
+		found := false
+		for _, revision := range fake.revisions {
+			if ParseRevisionName(revision.Name) == target.Revision {
+				found = true
+			}
+		}
+
+		if !found {
+			return status.Errorf(codes.NotFound, "revision %s not found", target.Revision)
+		}
+	}
+	return nil
+}
+
+func (f *FakeBackend) SetLabels(ctx context.Context, serviceName string, labels map[string]string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.injected("SetLabels", serviceName); err != nil {
+		return err
+	}
+
+	fake, err := f.lookup(serviceName)
+	if err != nil {
+		return err
+	}
+
+	fake.service.Labels = labels
+	f.mutate(fake)
+	return nil
+}
+
+func (f *FakeBackend) Delete(ctx context.Context, serviceName string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.injected("Delete", serviceName); err != nil {
+		return err
+	}
+
+	if _, err := f.lookup(serviceName); err != nil {
+		return err
+	}
+
+	delete(f.services, serviceName)
+	return nil
+}
+
+func (f *FakeBackend) DeleteRevision(ctx context.Context, serviceName, revisionID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.injected("DeleteRevision", serviceName); err != nil {
+		return err
+	}
+
+	fake, err := f.lookup(serviceName)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range fake.service.TrafficStatuses {
+		revision := target.Revision
+		if target.Type == pb.TrafficTargetAllocationType_TRAFFIC_TARGET_ALLOCATION_TYPE_LATEST {
+			revision = ParseRevisionName(fake.service.LatestReadyRevision)
+		}
+
+		if revision == revisionID {
+			return status.Errorf(codes.FailedPrecondition, "revision %s is receiving traffic", revisionID)
+		}
+	}
+
+	for idx, revision := range fake.revisions {
+		if ParseRevisionName(revision.Name) == revisionID {
+			fake.revisions = append(fake.revisions[:idx], fake.revisions[idx+1:]...)
+			return nil
+		}
+	}
+
+	return status.Errorf(codes.NotFound, "revision %s not found", revisionID)
+}
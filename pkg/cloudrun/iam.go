@@ -0,0 +1,161 @@
+package cloudrun
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	iampb "cloud.google.com/go/iam/apiv1/iampb"
+	"github.com/angelini/sblocks/internal/log"
+	"github.com/angelini/sblocks/internal/maps"
+	"go.uber.org/zap"
+)
+
+const (
+	InvokerRole = "roles/run.invoker"
+	AllUsers    = "allUsers"
+)
+
+var memberPrefixes = []string{"user:", "serviceAccount:", "group:", "domain:", "principal:", "principalSet:"}
+
+func ValidateMember(member string) error {
+	if member == AllUsers || member == "allAuthenticatedUsers" {
+		return nil
+	}
+
+	for _, prefix := range memberPrefixes {
+		if strings.HasPrefix(member, prefix) && len(member) > len(prefix) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid IAM member %q, expected allUsers, allAuthenticatedUsers or a prefix like serviceAccount:", member)
+}
+
+func invokerMembers(policy *iampb.Policy) []string {
+	members := []string{}
+	for _, binding := range policy.Bindings {
+		if binding.Role == InvokerRole && binding.Condition == nil {
+			members = append(members, binding.Members...)
+		}
+	}
+	sort.Strings(members)
+	return members
+}
+
+// editInvokers adds and removes members of the unconditional invoker binding, leaving
+// every other binding of the policy as it was. It reports whether the policy changed.
+func editInvokers(policy *iampb.Policy, add, remove []string) bool {
+	var binding *iampb.Binding
+	for _, existing := range policy.Bindings {
+		if existing.Role == InvokerRole && existing.Condition == nil {
+			binding = existing
+			break
+		}
+	}
+
+	if binding == nil {
+		if len(add) == 0 {
+			return false
+		}
+		binding = &iampb.Binding{Role: InvokerRole}
+		policy.Bindings = append(policy.Bindings, binding)
+	}
+
+	members := make(map[string]bool, len(binding.Members)+len(add))
+	for _, member := range binding.Members {
+		members[member] = true
+	}
+
+	changed := false
+	for _, member := range add {
+		if !members[member] {
+			members[member] = true
+			changed = true
+		}
+	}
+	for _, member := range remove {
+		if members[member] {
+			delete(members, member)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return false
+	}
+
+	binding.Members = maps.SortedKeys(members)
+	if len(binding.Members) == 0 {
+		bindings := make([]*iampb.Binding, 0, len(policy.Bindings))
+		for _, existing := range policy.Bindings {
+			if existing != binding {
+				bindings = append(bindings, existing)
+			}
+		}
+		policy.Bindings = bindings
+	}
+
+	return true
+}
+
+func updateInvokers(ctx context.Context, client Backend, serviceName string, add, remove []string) error {
+	return client.UpdateIamPolicy(ctx, serviceName, func(policy *iampb.Policy) bool {
+		return editInvokers(policy, add, remove)
+	})
+}
+
+// Invokers lists the members allowed to invoke each service of the block.
+func (sb *ServiceBlock) Invokers(ctx context.Context, client Backend) (map[string][]string, error) {
+	invokers := make(map[string][]string, len(sb.services))
+	for _, service := range maps.SortedValues(sb.services) {
+		policy, err := client.GetIamPolicy(ctx, service.name)
+		if err != nil {
+			return nil, err
+		}
+		invokers[service.name] = invokerMembers(policy)
+	}
+	return invokers, nil
+}
+
+// UpdateInvokers adds and removes invoker members on every service of the block. Other
+// bindings in the service policies are kept.
+func (sb *ServiceBlock) UpdateInvokers(ctx context.Context, client Backend, add, remove []string) error {
+	for _, member := range append(append([]string{}, add...), remove...) {
+		err := ValidateMember(member)
+		if err != nil {
+			return err
+		}
+	}
+
+	group, groupCtx := newGroup(ctx, client)
+
+	for _, service := range sb.services {
+		serviceName := service.name
+		group.Go(func() error {
+			return updateInvokers(groupCtx, client, serviceName, add, remove)
+		})
+	}
+
+	err := group.Wait()
+	if err != nil {
+		return err
+	}
+
+	log.Info(ctx, "updated block invokers", zap.String("block", sb.name), zap.Strings("add", add), zap.Strings("remove", remove))
+	return nil
+}
+
+func (sb *ServiceBlock) AllowPublicAccess(ctx context.Context, client Backend) error {
+	return sb.UpdateInvokers(ctx, client, []string{AllUsers}, nil)
+}
+
+func isPublicPolicy(policy *iampb.Policy) bool {
+	for _, member := range invokerMembers(policy) {
+		if member == AllUsers {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package cloudrun
+
+import (
+	"fmt"
+	"strings"
+
+	pb "cloud.google.com/go/run/apiv2/runpb"
+)
+
+type Ingress string
+
+const (
+	IngressAll                  Ingress = "all"
+	IngressInternal             Ingress = "internal"
+	IngressInternalLoadBalancer Ingress = "internal-and-cloud-load-balancing"
+)
+
+var ingressValues = map[Ingress]pb.IngressTraffic{
+	IngressAll:                  pb.IngressTraffic_INGRESS_TRAFFIC_ALL,
+	IngressInternal:             pb.IngressTraffic_INGRESS_TRAFFIC_INTERNAL_ONLY,
+	IngressInternalLoadBalancer: pb.IngressTraffic_INGRESS_TRAFFIC_INTERNAL_LOAD_BALANCER,
+}
+
+func IngressDefinition(ingress pb.IngressTraffic) Ingress {
+	for key, value := range ingressValues {
+		if value == ingress {
+			return key
+		}
+	}
+	return IngressAll
+}
+
+func asPbIngress(ingress Ingress) pb.IngressTraffic {
+	if value, found := ingressValues[ingress]; found {
+		return value
+	}
+	return pb.IngressTraffic_INGRESS_TRAFFIC_ALL
+}
+
+func (i Ingress) validate() error {
+	if i == "" {
+		return nil
+	}
+
+	if _, found := ingressValues[i]; !found {
+		return fmt.Errorf("unsupported ingress %q", i)
+	}
+	return nil
+}
+
+type VPCEgress string
+
+const (
+	EgressAllTraffic        VPCEgress = "all-traffic"
+	EgressPrivateRangesOnly VPCEgress = "private-ranges-only"
+)
+
+var egressValues = map[VPCEgress]pb.VpcAccess_VpcEgress{
+	EgressAllTraffic:        pb.VpcAccess_ALL_TRAFFIC,
+	EgressPrivateRangesOnly: pb.VpcAccess_PRIVATE_RANGES_ONLY,
+}
+
+// VPCAccess either routes egress through a Serverless VPC Access connector, or
+// uses direct VPC egress when Network or Subnetwork is set.
+type VPCAccess struct {
+	Connector  string
+	Network    string
+	Subnetwork string
+	Tags       []string
+	Egress     VPCEgress
+}
+
+func VPCAccessDefinition(access *pb.VpcAccess) *VPCAccess {
+	if access == nil {
+		return nil
+	}
+
+	result := &VPCAccess{
+		Connector: access.Connector,
+	}
+
+	for key, value := range egressValues {
+		if value == access.Egress {
+			result.Egress = key
+		}
+	}
+
+	if len(access.NetworkInterfaces) > 0 {
+		networkInterface := access.NetworkInterfaces[0]
+		result.Network = networkInterface.Network
+		result.Subnetwork = networkInterface.Subnetwork
+		result.Tags = networkInterface.Tags
+	}
+
+	return result
+}
+
+func asPbVPCAccess(access *VPCAccess) *pb.VpcAccess {
+	if access == nil {
+		return nil
+	}
+
+	result := &pb.VpcAccess{
+		Connector: access.Connector,
+		Egress:    egressValues[access.Egress],
+	}
+
+	if access.Network != "" || access.Subnetwork != "" {
+		result.NetworkInterfaces = []*pb.VpcAccess_NetworkInterface{
+			{
+				Network:    access.Network,
+				Subnetwork: access.Subnetwork,
+				Tags:       access.Tags,
+			},
+		}
+	}
+
+	return result
+}
+
+func (v *VPCAccess) validate() error {
+	direct := v.Network != "" || v.Subnetwork != ""
+
+	if v.Connector != "" && direct {
+		return fmt.Errorf("a connector cannot be combined with direct VPC egress")
+	}
+
+	if v.Connector == "" && !direct {
+		return fmt.Errorf("either a connector or a network must be set")
+	}
+
+	if _, found := egressValues[v.Egress]; v.Egress != "" && !found {
+		return fmt.Errorf("unsupported egress %q", v.Egress)
+	}
+
+	return nil
+}
+
+func (v *VPCAccess) String() string {
+	entries := []string{}
+	if v.Connector != "" {
+		entries = append(entries, fmt.Sprintf("connector=%s", v.Connector))
+	}
+	if v.Network != "" {
+		entries = append(entries, fmt.Sprintf("network=%s", v.Network))
+	}
+	if v.Subnetwork != "" {
+		entries = append(entries, fmt.Sprintf("subnetwork=%s", v.Subnetwork))
+	}
+	if len(v.Tags) > 0 {
+		entries = append(entries, fmt.Sprintf("tags=%s", strings.Join(v.Tags, "|")))
+	}
+	if v.Egress != "" {
+		entries = append(entries, fmt.Sprintf("egress=%s", v.Egress))
+	}
+
+	return strings.Join(entries, ", ")
+}
@@ -0,0 +1,277 @@
+package cloudrun
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/angelini/sblocks/internal/log"
+	"github.com/angelini/sblocks/internal/maps"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Operation records a long running Cloud Run operation so that it can be waited on again
+// after the process that started it exits.
+type Operation struct {
+	Name      string    `json:"name"`
+	Method    string    `json:"method"`
+	Service   string    `json:"service"`
+	Revision  string    `json:"revision,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+func (o *Operation) String() string {
+	target := o.Service
+	if o.Revision != "" {
+		target = fmt.Sprintf("%s/%s", o.Service, o.Revision)
+	}
+	return fmt.Sprintf("%s %s (started %s)", o.Method, target, o.StartedAt.Format(time.RFC3339))
+}
+
+type OperationStore interface {
+	List(ctx context.Context) ([]Operation, error)
+	Save(ctx context.Context, operation Operation) error
+	Delete(ctx context.Context, name string) error
+}
+
+// FileOperationStore keeps pending operations in a local JSON file.
+type FileOperationStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileOperationStore(path string) *FileOperationStore {
+	return &FileOperationStore{path: path}
+}
+
+func (s *FileOperationStore) read() (map[string]Operation, error) {
+	operations := make(map[string]Operation)
+
+	content, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return operations, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(content, &operations)
+	if err != nil {
+		return nil, fmt.Errorf("invalid operations file %s: %w", s.path, err)
+	}
+
+	return operations, nil
+}
+
+func (s *FileOperationStore) write(operations map[string]Operation) error {
+	content, err := json.MarshalIndent(operations, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(s.path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	tmp := s.path + ".tmp"
+	err = os.WriteFile(tmp, content, 0o644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.path)
+}
+
+func (s *FileOperationStore) List(ctx context.Context) ([]Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	operations, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	return maps.SortedValues(operations), nil
+}
+
+func (s *FileOperationStore) Save(ctx context.Context, operation Operation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	operations, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	operations[operation.Name] = operation
+	return s.write(operations)
+}
+
+func (s *FileOperationStore) Delete(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	operations, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	if _, found := operations[name]; !found {
+		return nil
+	}
+
+	delete(operations, name)
+	return s.write(operations)
+}
+
+// WithOperationStore persists every long running operation until it completes.
+func WithOperationStore(store OperationStore) ClientOption {
+	return func(c *clientConfig) {
+		c.operations = store
+	}
+}
+
+// wait runs fn with the operation timeout while the operation is recorded in the store.
+// Operations interrupted by the context are left in the store to be resumed later.
+func (c *Client) wait(ctx context.Context, operation Operation, fn func(context.Context) error) error {
+	store := c.config.operations
+	if store != nil {
+		if operation.StartedAt.IsZero() {
+			operation.StartedAt = time.Now()
+		}
+
+		err := store.Save(ctx, operation)
+		if err != nil {
+			log.Warn(ctx, "failed to record operation", zap.String("operation", operation.Name), zap.Error(err))
+		}
+	}
+
+	waitCtx, cancel := withTimeout(ctx, c.config.operationTimeout)
+	defer cancel()
+
+	err := fn(waitCtx)
+	if err != nil && waitCtx.Err() != nil {
+		return err
+	}
+
+	if store != nil {
+		deleteErr := store.Delete(ctx, operation.Name)
+		if deleteErr != nil {
+			log.Warn(ctx, "failed to clear operation", zap.String("operation", operation.Name), zap.Error(deleteErr))
+		}
+	}
+
+	return err
+}
+
+// poll reports whether a recorded operation has finished, successfully or not.
+func (c *Client) poll(ctx context.Context, operation Operation) (bool, error) {
+	var err error
+	done := false
+
+	switch operation.Method {
+	case "CreateService":
+		op := c.services.CreateServiceOperation(operation.Name)
+		_, err = op.Poll(ctx)
+		done = op.Done()
+	case "UpdateService":
+		op := c.services.UpdateServiceOperation(operation.Name)
+		_, err = op.Poll(ctx)
+		done = op.Done()
+	case "DeleteService":
+		op := c.services.DeleteServiceOperation(operation.Name)
+		_, err = op.Poll(ctx)
+		done = op.Done()
+	case "DeleteRevision":
+		op := c.revisions.DeleteRevisionOperation(operation.Name)
+		_, err = op.Poll(ctx)
+		done = op.Done()
+	default:
+		return false, fmt.Errorf("unsupported operation method %q", operation.Method)
+	}
+
+	if done {
+		return true, nil
+	}
+	return false, err
+}
+
+// PendingOperations lists the recorded operations that are still running, clearing the
+// ones that finished or expired since they were recorded. Operations that cannot be
+// polled are kept as pending.
+func (c *Client) PendingOperations(ctx context.Context) ([]Operation, error) {
+	store := c.config.operations
+	if store == nil {
+		return nil, nil
+	}
+
+	operations, err := store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []Operation{}
+	for _, operation := range operations {
+		// the store can be shared by clients for other locations
+		if !strings.HasPrefix(operation.Name, c.Parent+"/") {
+			continue
+		}
+
+		done, err := c.poll(ctx, operation)
+		if status.Code(err) == codes.NotFound {
+			// operations expire after a while, so one that is gone has long finished
+			done = true
+		} else if err != nil {
+			log.Warn(ctx, "cannot poll operation", zap.String("operation", operation.Name), zap.String("service", operation.Service), zap.Error(err))
+		}
+
+		if done {
+			err = store.Delete(ctx, operation.Name)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		pending = append(pending, operation)
+	}
+
+	return pending, nil
+}
+
+// WaitOperation resumes waiting on a recorded operation until it completes.
+func (c *Client) WaitOperation(ctx context.Context, operation Operation) error {
+	log.Info(ctx, "start wait operation", zap.String("method", operation.Method), zap.String("service", operation.Service))
+
+	err := c.wait(ctx, operation, func(ctx context.Context) error {
+		var err error
+		switch operation.Method {
+		case "CreateService":
+			_, err = c.services.CreateServiceOperation(operation.Name).Wait(ctx)
+		case "UpdateService":
+			_, err = c.services.UpdateServiceOperation(operation.Name).Wait(ctx)
+		case "DeleteService":
+			_, err = c.services.DeleteServiceOperation(operation.Name).Wait(ctx)
+		case "DeleteRevision":
+			_, err = c.revisions.DeleteRevisionOperation(operation.Name).Wait(ctx)
+		default:
+			err = fmt.Errorf("unsupported operation method %q", operation.Method)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info(ctx, "finished wait operation", zap.String("method", operation.Method), zap.String("service", operation.Service))
+	return nil
+}
@@ -0,0 +1,138 @@
+package cloudrun
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	pb "cloud.google.com/go/run/apiv2/runpb"
+)
+
+type ProbeType string
+
+const (
+	HTTPProbe ProbeType = "http"
+	TCPProbe  ProbeType = "tcp"
+	GRPCProbe ProbeType = "grpc"
+)
+
+type Probe struct {
+	Type             ProbeType
+	Path             string
+	Port             uint32
+	Service          string
+	InitialDelay     time.Duration
+	Timeout          time.Duration
+	Period           time.Duration
+	FailureThreshold uint32
+}
+
+func ProbeDefinition(probe *pb.Probe) *Probe {
+	if probe == nil {
+		return nil
+	}
+
+	result := &Probe{
+		InitialDelay:     time.Duration(probe.InitialDelaySeconds) * time.Second,
+		Timeout:          time.Duration(probe.TimeoutSeconds) * time.Second,
+		Period:           time.Duration(probe.PeriodSeconds) * time.Second,
+		FailureThreshold: uint32(probe.FailureThreshold),
+	}
+
+	switch action := probe.ProbeType.(type) {
+	case *pb.Probe_HttpGet:
+		result.Type = HTTPProbe
+		result.Path = action.HttpGet.Path
+		result.Port = uint32(action.HttpGet.Port)
+	case *pb.Probe_TcpSocket:
+		result.Type = TCPProbe
+		result.Port = uint32(action.TcpSocket.Port)
+	case *pb.Probe_Grpc:
+		result.Type = GRPCProbe
+		result.Port = uint32(action.Grpc.Port)
+		result.Service = action.Grpc.Service
+	}
+
+	return result
+}
+
+func asPbProbe(probe *Probe) *pb.Probe {
+	if probe == nil {
+		return nil
+	}
+
+	result := &pb.Probe{
+		InitialDelaySeconds: int32(probe.InitialDelay.Seconds()),
+		TimeoutSeconds:      int32(probe.Timeout.Seconds()),
+		PeriodSeconds:       int32(probe.Period.Seconds()),
+		FailureThreshold:    int32(probe.FailureThreshold),
+	}
+
+	switch probe.Type {
+	case HTTPProbe:
+		result.ProbeType = &pb.Probe_HttpGet{
+			HttpGet: &pb.HTTPGetAction{Path: probe.Path, Port: int32(probe.Port)},
+		}
+	case TCPProbe:
+		result.ProbeType = &pb.Probe_TcpSocket{
+			TcpSocket: &pb.TCPSocketAction{Port: int32(probe.Port)},
+		}
+	case GRPCProbe:
+		result.ProbeType = &pb.Probe_Grpc{
+			Grpc: &pb.GRPCAction{Port: int32(probe.Port), Service: probe.Service},
+		}
+	}
+
+	return result
+}
+
+// Limits follow https://cloud.google.com/run/docs/configuring/healthchecks
+func (p *Probe) validate(maxDelay time.Duration, allowed ...ProbeType) error {
+	found := false
+	for _, probeType := range allowed {
+		if p.Type == probeType {
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("unsupported probe type %q", p.Type)
+	}
+
+	if p.InitialDelay < 0 || p.InitialDelay > maxDelay {
+		return fmt.Errorf("initial delay %s must be between 0s and %s", p.InitialDelay, maxDelay)
+	}
+
+	if p.Period < 0 || p.Period > maxDelay {
+		return fmt.Errorf("period %s must be between 0s and %s", p.Period, maxDelay)
+	}
+
+	if p.Timeout < 0 || (p.Period > 0 && p.Timeout > p.Period) {
+		return fmt.Errorf("timeout %s must not exceed the period %s", p.Timeout, p.Period)
+	}
+
+	if p.Type == HTTPProbe && p.Path != "" && !strings.HasPrefix(p.Path, "/") {
+		return fmt.Errorf("http probe path %q must start with /", p.Path)
+	}
+
+	return nil
+}
+
+func (p *Probe) String() string {
+	target := ""
+	switch p.Type {
+	case HTTPProbe:
+		target = p.Path
+		if p.Port != 0 {
+			target = fmt.Sprintf(":%d%s", p.Port, p.Path)
+		}
+	case TCPProbe:
+		target = fmt.Sprintf(":%d", p.Port)
+	case GRPCProbe:
+		target = fmt.Sprintf("%s:%d", p.Service, p.Port)
+	}
+
+	return fmt.Sprintf(
+		"%s %s (delay=%s, timeout=%s, period=%s, failures=%d)",
+		p.Type, target, p.InitialDelay, p.Timeout, p.Period, p.FailureThreshold,
+	)
+}
@@ -0,0 +1,345 @@
+package cloudrun
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	iampb "cloud.google.com/go/iam/apiv1/iampb"
+	pb "cloud.google.com/go/run/apiv2/runpb"
+	"github.com/angelini/sblocks/internal/maps"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Placement picks the region of the service at a given index within its block.
+type Placement interface {
+	Region(index int) string
+	Regions() []string
+}
+
+type RoundRobin []string
+
+func (r RoundRobin) Region(index int) string {
+	return r[index%len(r)]
+}
+
+func (r RoundRobin) Regions() []string {
+	return r
+}
+
+type RegionWeight struct {
+	Region string
+	Weight int
+}
+
+// Weighted places consecutive indexes in each region according to its weight, so
+// {a: 2, b: 1} places indexes 0 and 1 in a and index 2 in b, then starts over.
+type Weighted []RegionWeight
+
+// Validate rejects placements without regions or with weights below 1, which could not
+// place any index.
+func (w Weighted) Validate() error {
+	if len(w) == 0 {
+		return fmt.Errorf("weighted placement has no regions")
+	}
+
+	for _, region := range w {
+		if region.Weight < 1 {
+			return fmt.Errorf("invalid weight %d for region %s", region.Weight, region.Region)
+		}
+	}
+
+	return nil
+}
+
+// Region expects a placement that passed Validate.
+func (w Weighted) Region(index int) string {
+	total := 0
+	for _, region := range w {
+		total += region.Weight
+	}
+
+	slot := index % total
+	for _, region := range w[:len(w)-1] {
+		if slot < region.Weight {
+			return region.Region
+		}
+		slot -= region.Weight
+	}
+
+	return w[len(w)-1].Region
+}
+
+func (w Weighted) Regions() []string {
+	regions := make([]string, 0, len(w))
+	for _, region := range w {
+		regions = append(regions, region.Region)
+	}
+	return regions
+}
+
+// ParsePlacement reads a comma separated list of regions, each optionally followed by
+// "=<weight>". Regions without weights are placed round-robin.
+func ParsePlacement(value string) (Placement, error) {
+	weighted := Weighted{}
+	hasWeights := false
+
+	for _, entry := range strings.Split(value, ",") {
+		region, weight, found := strings.Cut(strings.TrimSpace(entry), "=")
+		if region == "" {
+			return nil, fmt.Errorf("invalid region list %q", value)
+		}
+
+		parsed := 1
+		if found {
+			var err error
+			parsed, err = strconv.Atoi(weight)
+			if err != nil || parsed < 1 {
+				return nil, fmt.Errorf("invalid weight %q for region %s", weight, region)
+			}
+			hasWeights = true
+		}
+
+		for _, existing := range weighted {
+			if existing.Region == region {
+				return nil, fmt.Errorf("region %s is listed twice", region)
+			}
+		}
+
+		weighted = append(weighted, RegionWeight{Region: region, Weight: parsed})
+	}
+
+	if hasWeights {
+		return weighted, nil
+	}
+	return RoundRobin(weighted.Regions()), nil
+}
+
+// MultiRegionBackend spreads the services of a block across one backend per region and
+// routes every later call to the region that holds the service.
+type MultiRegionBackend struct {
+	backends  map[string]Backend
+	placement Placement
+
+	mu      sync.Mutex
+	regions map[string]string
+}
+
+var _ Backend = (*MultiRegionBackend)(nil)
+
+func NewMultiRegionBackend(backends map[string]Backend, placement Placement) (*MultiRegionBackend, error) {
+	if len(placement.Regions()) == 0 {
+		return nil, fmt.Errorf("placement has no regions")
+	}
+
+	if weighted, ok := placement.(Weighted); ok {
+		err := weighted.Validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, region := range placement.Regions() {
+		if _, found := backends[region]; !found {
+			return nil, fmt.Errorf("missing backend for region %s", region)
+		}
+	}
+
+	return &MultiRegionBackend{
+		backends:  backends,
+		placement: placement,
+		regions:   make(map[string]string),
+	}, nil
+}
+
+func (m *MultiRegionBackend) Close() error {
+	var result error
+	for _, backend := range m.backends {
+		if closer, ok := backend.(io.Closer); ok {
+			err := closer.Close()
+			if err != nil && result == nil {
+				result = err
+			}
+		}
+	}
+	return result
+}
+
+func (m *MultiRegionBackend) Concurrency() int {
+	limit := 0
+	for _, backend := range m.backends {
+		if backend.Concurrency() > limit {
+			limit = backend.Concurrency()
+		}
+	}
+	return limit
+}
+
+func (m *MultiRegionBackend) record(serviceName, region string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.regions[serviceName] = region
+}
+
+func (m *MultiRegionBackend) lookup(ctx context.Context, serviceName string) (Backend, error) {
+	m.mu.Lock()
+	region, found := m.regions[serviceName]
+	m.mu.Unlock()
+
+	if !found {
+		_, err := m.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		m.mu.Lock()
+		region, found = m.regions[serviceName]
+		m.mu.Unlock()
+	}
+
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "service %s not found in any region", serviceName)
+	}
+	return m.backends[region], nil
+}
+
+func (m *MultiRegionBackend) Create(ctx context.Context, name string, labels map[string]string, revision *Revision) (*pb.Service, error) {
+	index, err := strconv.Atoi(labels[IndexLabel])
+	if err != nil {
+		index, err = strconv.Atoi(name[strings.LastIndex(name, "-")+1:])
+		if err != nil {
+			index = 0
+		}
+	}
+
+	region := m.placement.Region(index)
+	service, err := m.backends[region].Create(ctx, name, labels, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	m.record(name, region)
+	return service, nil
+}
+
+func (m *MultiRegionBackend) List(ctx context.Context) ([]*pb.Service, error) {
+	result := []*pb.Service{}
+	regions := make(map[string]string)
+
+	for _, region := range maps.SortedKeys(m.backends) {
+		services, err := m.backends[region].List(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list services in %s: %w", region, err)
+		}
+
+		for _, service := range services {
+			serviceName := ParseServiceName(service.Name)
+			if existing, found := regions[serviceName]; found {
+				return nil, fmt.Errorf("service %s exists in both %s and %s", serviceName, existing, region)
+			}
+			regions[serviceName] = region
+		}
+		result = append(result, services...)
+	}
+
+	m.mu.Lock()
+	m.regions = regions
+	m.mu.Unlock()
+
+	return result, nil
+}
+
+func (m *MultiRegionBackend) ListRevisions(ctx context.Context, serviceName string) ([]*pb.Revision, error) {
+	backend, err := m.lookup(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	return backend.ListRevisions(ctx, serviceName)
+}
+
+func (m *MultiRegionBackend) Update(ctx context.Context, serviceName string, labels map[string]string, revision *Revision, traffic []TrafficTarget) error {
+	backend, err := m.lookup(ctx, serviceName)
+	if err != nil {
+		return err
+	}
+	return backend.Update(ctx, serviceName, labels, revision, traffic)
+}
+
+func (m *MultiRegionBackend) SetTraffic(ctx context.Context, serviceName string, targets []TrafficTarget) error {
+	backend, err := m.lookup(ctx, serviceName)
+	if err != nil {
+		return err
+	}
+	return backend.SetTraffic(ctx, serviceName, targets)
+}
+
+func (m *MultiRegionBackend) SetLabels(ctx context.Context, serviceName string, labels map[string]string) error {
+	backend, err := m.lookup(ctx, serviceName)
+	if err != nil {
+		return err
+	}
+	return backend.SetLabels(ctx, serviceName, labels)
+}
+
+func (m *MultiRegionBackend) GetIamPolicy(ctx context.Context, serviceName string) (*iampb.Policy, error) {
+	backend, err := m.lookup(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	return backend.GetIamPolicy(ctx, serviceName)
+}
+
+func (m *MultiRegionBackend) UpdateIamPolicy(ctx context.Context, serviceName string, update func(*iampb.Policy) bool) error {
+	backend, err := m.lookup(ctx, serviceName)
+	if err != nil {
+		return err
+	}
+	return backend.UpdateIamPolicy(ctx, serviceName, update)
+}
+
+func (m *MultiRegionBackend) Delete(ctx context.Context, serviceName string) error {
+	backend, err := m.lookup(ctx, serviceName)
+	if err != nil {
+		return err
+	}
+	return backend.Delete(ctx, serviceName)
+}
+
+func (m *MultiRegionBackend) DeleteRevision(ctx context.Context, serviceName, revisionID string) error {
+	backend, err := m.lookup(ctx, serviceName)
+	if err != nil {
+		return err
+	}
+	return backend.DeleteRevision(ctx, serviceName, revisionID)
+}
+
+func (m *MultiRegionBackend) PendingOperations(ctx context.Context) ([]Operation, error) {
+	result := []Operation{}
+	for _, region := range maps.SortedKeys(m.backends) {
+		operations, err := m.backends[region].PendingOperations(ctx)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, operations...)
+	}
+	return result, nil
+}
+
+func (m *MultiRegionBackend) WaitOperation(ctx context.Context, operation Operation) error {
+	if strings.Count(operation.Name, "/") >= 4 {
+		if backend, found := m.backends[ParseLocation(operation.Name)]; found {
+			return backend.WaitOperation(ctx, operation)
+		}
+	}
+
+	backend, err := m.lookup(ctx, operation.Service)
+	if err != nil {
+		return err
+	}
+	return backend.WaitOperation(ctx, operation)
+}
@@ -0,0 +1,167 @@
+package cloudrun
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultCPU    = "1"
+	defaultMemory = "512Mi"
+)
+
+type Resources struct {
+	CPU                string
+	Memory             string
+	CPUAlwaysAllocated bool
+}
+
+func (r *Resources) limits() (string, string) {
+	cpu := r.CPU
+	if cpu == "" {
+		cpu = defaultCPU
+	}
+
+	memory := r.Memory
+	if memory == "" {
+		memory = defaultMemory
+	}
+
+	return cpu, memory
+}
+
+func (r *Resources) String() string {
+	cpu, memory := r.limits()
+
+	allocation := "request"
+	if r.CPUAlwaysAllocated {
+		allocation = "always"
+	}
+
+	return fmt.Sprintf("cpu=%s, memory=%s, allocation=%s", cpu, memory, allocation)
+}
+
+// normalized formats the limits in millicores and bytes, so equivalent values like "1"
+// and "1000m" compare equal. Limits that fail to parse are kept as written.
+func (r *Resources) normalized() string {
+	cpu, memory := r.limits()
+
+	if milli, err := parseMilliCPU(cpu); err == nil {
+		cpu = fmt.Sprintf("%dm", milli)
+	}
+	if bytes, err := parseMemoryBytes(memory); err == nil {
+		memory = strconv.FormatInt(bytes, 10)
+	}
+
+	return fmt.Sprintf("cpu=%s, memory=%s, allocation=%t", cpu, memory, r.CPUAlwaysAllocated)
+}
+
+func parseMilliCPU(cpu string) (int64, error) {
+	if strings.HasSuffix(cpu, "m") {
+		return strconv.ParseInt(strings.TrimSuffix(cpu, "m"), 10, 64)
+	}
+
+	value, err := strconv.ParseFloat(cpu, 64)
+	if err != nil {
+		return 0, err
+	}
+	return int64(value * 1000), nil
+}
+
+var memorySuffixes = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"Ki", 1 << 10},
+	{"Mi", 1 << 20},
+	{"Gi", 1 << 30},
+	{"k", 1000},
+	{"M", 1000 * 1000},
+	{"G", 1000 * 1000 * 1000},
+}
+
+func parseMemoryBytes(memory string) (int64, error) {
+	for _, entry := range memorySuffixes {
+		if strings.HasSuffix(memory, entry.suffix) {
+			value, err := strconv.ParseInt(strings.TrimSuffix(memory, entry.suffix), 10, 64)
+			if err != nil {
+				return 0, err
+			}
+			return value * entry.multiplier, nil
+		}
+	}
+
+	return strconv.ParseInt(memory, 10, 64)
+}
+
+// Allowed combinations follow https://cloud.google.com/run/docs/configuring/cpu and
+// https://cloud.google.com/run/docs/configuring/memory-limits
+func (r *Resources) Validate(maxConcurrency uint32) error {
+	cpu, memory := r.limits()
+
+	milliCPU, err := parseMilliCPU(cpu)
+	if err != nil {
+		return fmt.Errorf("invalid cpu %q: %w", cpu, err)
+	}
+
+	memoryBytes, err := parseMemoryBytes(memory)
+	if err != nil {
+		return fmt.Errorf("invalid memory %q: %w", memory, err)
+	}
+
+	switch {
+	case milliCPU < 80:
+		return fmt.Errorf("cpu %s is below the minimum of 0.08", cpu)
+	case milliCPU < 1000:
+		if r.CPUAlwaysAllocated {
+			return fmt.Errorf("cpu %s below 1 requires request-only cpu allocation", cpu)
+		}
+		if maxConcurrency != 1 {
+			return fmt.Errorf("cpu %s below 1 requires a max concurrency of 1", cpu)
+		}
+		if memoryBytes > 512<<20 {
+			return fmt.Errorf("cpu %s below 1 supports at most 512Mi of memory", cpu)
+		}
+	case milliCPU == 1000 || milliCPU == 2000:
+	case milliCPU == 4000:
+		if memoryBytes < 2<<30 {
+			return fmt.Errorf("cpu %s requires at least 2Gi of memory", cpu)
+		}
+	case milliCPU == 6000 || milliCPU == 8000:
+		if memoryBytes < 4<<30 {
+			return fmt.Errorf("cpu %s requires at least 4Gi of memory", cpu)
+		}
+	default:
+		return fmt.Errorf("cpu %s must be below 1 or one of 1, 2, 4, 6 or 8", cpu)
+	}
+
+	memoryLimits := []struct {
+		above    int64
+		minCPU   int64
+		readable string
+	}{
+		{24 << 30, 8000, "24Gi"},
+		{16 << 30, 6000, "16Gi"},
+		{8 << 30, 4000, "8Gi"},
+		{4 << 30, 2000, "4Gi"},
+	}
+
+	switch {
+	case memoryBytes < 128<<20:
+		return fmt.Errorf("memory %s is below the minimum of 128Mi", memory)
+	case memoryBytes > 32<<30:
+		return fmt.Errorf("memory %s is above the maximum of 32Gi", memory)
+	}
+
+	for _, limit := range memoryLimits {
+		if memoryBytes > limit.above {
+			if milliCPU < limit.minCPU {
+				return fmt.Errorf("memory %s above %s requires at least %d cpu", memory, limit.readable, limit.minCPU/1000)
+			}
+			break
+		}
+	}
+
+	return nil
+}
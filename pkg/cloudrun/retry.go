@@ -0,0 +1,123 @@
+package cloudrun
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/angelini/sblocks/internal/log"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type ClientOption func(*clientConfig)
+
+type clientConfig struct {
+	concurrency      int
+	maxAttempts      int
+	initialBackoff   time.Duration
+	maxBackoff       time.Duration
+	callTimeout      time.Duration
+	operationTimeout time.Duration
+	operations       OperationStore
+}
+
+func defaultClientConfig() clientConfig {
+	return clientConfig{
+		concurrency:      4,
+		maxAttempts:      5,
+		initialBackoff:   500 * time.Millisecond,
+		maxBackoff:       30 * time.Second,
+		callTimeout:      30 * time.Second,
+		operationTimeout: 10 * time.Minute,
+	}
+}
+
+// WithConcurrency limits how many services a block operation works on at once, 0 removes the limit.
+func WithConcurrency(limit int) ClientOption {
+	return func(c *clientConfig) {
+		c.concurrency = limit
+	}
+}
+
+// WithRetry retries calls failing with a retryable code up to maxAttempts times, doubling
+// a jittered backoff between attempts up to maxBackoff.
+func WithRetry(maxAttempts int, initialBackoff, maxBackoff time.Duration) ClientOption {
+	return func(c *clientConfig) {
+		c.maxAttempts = maxAttempts
+		c.initialBackoff = initialBackoff
+		c.maxBackoff = maxBackoff
+	}
+}
+
+// WithCallTimeout bounds each API call attempt, 0 disables the timeout.
+func WithCallTimeout(timeout time.Duration) ClientOption {
+	return func(c *clientConfig) {
+		c.callTimeout = timeout
+	}
+}
+
+// WithOperationTimeout bounds the wait for a long running operation, 0 disables the timeout.
+func WithOperationTimeout(timeout time.Duration) ClientOption {
+	return func(c *clientConfig) {
+		c.operationTimeout = timeout
+	}
+}
+
+var retryableCodes = map[codes.Code]bool{
+	codes.ResourceExhausted: true,
+	codes.Unavailable:       true,
+	codes.Aborted:           true,
+}
+
+func isRetryable(err error) bool {
+	return retryableCodes[status.Code(err)]
+}
+
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// call runs fn with the per call timeout, retrying retryable errors with exponential
+// backoff and jitter.
+func (c *Client) call(ctx context.Context, method string, fn func(context.Context) error) error {
+	backoff := c.config.initialBackoff
+
+	for attempt := 1; ; attempt++ {
+		callCtx, cancel := withTimeout(ctx, c.config.callTimeout)
+		err := fn(callCtx)
+		cancel()
+
+		if err == nil || attempt >= c.config.maxAttempts || !isRetryable(err) {
+			return err
+		}
+
+		delay := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		log.Warn(ctx, "retry cloud run call", zap.String("method", method), zap.Int("attempt", attempt), zap.Duration("delay", delay), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		backoff *= 2
+		if backoff > c.config.maxBackoff {
+			backoff = c.config.maxBackoff
+		}
+	}
+}
+
+// newGroup starts an errgroup limited to the concurrency configured on the backend.
+func newGroup(ctx context.Context, client Backend) (*errgroup.Group, context.Context) {
+	group, ctx := errgroup.WithContext(ctx)
+	if limit := client.Concurrency(); limit > 0 {
+		group.SetLimit(limit)
+	}
+	return group, ctx
+}
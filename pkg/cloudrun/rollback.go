@@ -0,0 +1,131 @@
+package cloudrun
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/angelini/sblocks/internal/maps"
+)
+
+// TrafficError lists the services whose traffic could not be updated. The other services
+// of the block were updated.
+type TrafficError struct {
+	Failed map[string]error
+}
+
+func (e *TrafficError) Error() string {
+	failures := make([]string, 0, len(e.Failed))
+	for _, serviceName := range maps.SortedKeys(e.Failed) {
+		failures = append(failures, fmt.Sprintf("%s: %v", serviceName, e.Failed[serviceName]))
+	}
+	return fmt.Sprintf("traffic update failed on %d services: %s", len(e.Failed), strings.Join(failures, "; "))
+}
+
+// ServiceTraffic returns the traffic split of each service by revision name. Unlike
+// TrafficSplit, services are allowed to differ.
+func (sb *ServiceBlock) ServiceTraffic() map[string]map[string]int {
+	splits := make(map[string]map[string]int, len(sb.services))
+	for name, service := range sb.services {
+		splits[name] = service.trafficSplit()
+	}
+	return splits
+}
+
+// CheckRevision returns an error naming every service that no longer has the revision.
+func (sb *ServiceBlock) CheckRevision(name string) error {
+	missing := []string{}
+	for _, service := range maps.SortedValues(sb.services) {
+		if !service.hasRevision(name) {
+			missing = append(missing, service.name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("revision %s not found on services %s", name, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// PreviousRevision finds the newest revision that was created before the newest revision
+// receiving traffic and that still exists on every service.
+func (sb *ServiceBlock) PreviousRevision() (string, error) {
+	services := maps.SortedValues(sb.services)
+	if len(services) == 0 {
+		return "", fmt.Errorf("block %s has no services", sb.name)
+	}
+
+	revisions := maps.SortedValues(services[0].revisions)
+	sort.SliceStable(revisions, func(i, j int) bool {
+		return revisions[i].created.After(revisions[j].created)
+	})
+
+	serving := false
+	for _, revision := range revisions {
+		if !serving {
+			serving = revision.state.traffic > 0
+			continue
+		}
+
+		if !revision.state.isDeleted && sb.HasRevision(revision.definition.Name) {
+			return revision.definition.Name, nil
+		}
+	}
+
+	return "", fmt.Errorf("block %s has no revision older than the one serving traffic", sb.name)
+}
+
+// SetServiceTraffic applies a split to each service, continuing past failures so that every
+// service gets a chance to change. Failures are returned in a TrafficError.
+func (sb *ServiceBlock) SetServiceTraffic(ctx context.Context, client Backend, splits map[string]map[string]int) error {
+	for _, serviceName := range maps.SortedKeys(splits) {
+		service, found := sb.services[serviceName]
+		if !found {
+			return fmt.Errorf("service %s not found in block %s", serviceName, sb.name)
+		}
+
+		total := 0
+		for _, revision := range maps.SortedKeys(splits[serviceName]) {
+			if !service.hasRevision(revision) {
+				return fmt.Errorf("revision %s not found on service %s", revision, serviceName)
+			}
+			total += splits[serviceName][revision]
+		}
+
+		if total != 100 {
+			return fmt.Errorf("traffic percentages for service %s sum to %d, expected 100", serviceName, total)
+		}
+	}
+
+	var mu sync.Mutex
+	failed := make(map[string]error)
+	group, groupCtx := newGroup(ctx, client)
+
+	for _, serviceName := range maps.SortedKeys(splits) {
+		serviceName, targets := serviceName, sb.services[serviceName].splitTargets(splits[serviceName])
+
+		group.Go(func() error {
+			err := client.SetTraffic(groupCtx, serviceName, targets)
+			if err != nil {
+				mu.Lock()
+				failed[serviceName] = err
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+
+	group.Wait()
+
+	err := sb.Refresh(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	if len(failed) > 0 {
+		return &TrafficError{Failed: failed}
+	}
+	return nil
+}
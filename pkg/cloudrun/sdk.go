@@ -3,25 +3,38 @@ package cloudrun
 import (
 	"context"
 	"fmt"
-	"strings"
+	"time"
 
 	iampb "cloud.google.com/go/iam/apiv1/iampb"
 	run "cloud.google.com/go/run/apiv2"
 	"cloud.google.com/go/run/apiv2/runpb"
 	pb "cloud.google.com/go/run/apiv2/runpb"
 	"github.com/angelini/sblocks/internal/log"
+	"github.com/angelini/sblocks/internal/maps"
 	"go.uber.org/zap"
-	"golang.org/x/sync/errgroup"
 	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
+// managedDescription marks the services created by sblocks, it is sent on every create
+// and update since UpdateService replaces the whole service.
+const managedDescription = "Managed by sblocks"
+
 type Client struct {
 	Parent    string
 	services  *run.ServicesClient
 	revisions *run.RevisionsClient
+	config    clientConfig
 }
 
-func NewClient(ctx context.Context, project, location string) (*Client, error) {
+func NewClient(ctx context.Context, project, location string, options ...ClientOption) (*Client, error) {
+	config := defaultClientConfig()
+	for _, option := range options {
+		option(&config)
+	}
+
 	services, err := run.NewServicesClient(ctx)
 	if err != nil {
 		return nil, err
@@ -36,47 +49,171 @@ func NewClient(ctx context.Context, project, location string) (*Client, error) {
 		Parent:    fmt.Sprintf("projects/%s/locations/%s", project, location),
 		services:  services,
 		revisions: revisions,
+		config:    config,
 	}, nil
 }
 
+type Backend interface {
+	Create(ctx context.Context, name string, labels map[string]string, revision *Revision) (*pb.Service, error)
+	List(ctx context.Context) ([]*pb.Service, error)
+	ListRevisions(ctx context.Context, serviceName string) ([]*pb.Revision, error)
+	Update(ctx context.Context, serviceName string, labels map[string]string, revision *Revision, traffic []TrafficTarget) error
+	SetTraffic(ctx context.Context, serviceName string, targets []TrafficTarget) error
+	SetLabels(ctx context.Context, serviceName string, labels map[string]string) error
+	GetIamPolicy(ctx context.Context, serviceName string) (*iampb.Policy, error)
+	UpdateIamPolicy(ctx context.Context, serviceName string, update func(*iampb.Policy) bool) error
+	Delete(ctx context.Context, serviceName string) error
+	DeleteRevision(ctx context.Context, serviceName, revisionID string) error
+	PendingOperations(ctx context.Context) ([]Operation, error)
+	WaitOperation(ctx context.Context, operation Operation) error
+	Concurrency() int
+}
+
+var _ Backend = (*Client)(nil)
+
 func (c *Client) Close() error {
 	return c.services.Close()
 }
 
-func asPbContainers(containers map[string]Container) []*pb.Container {
-	result := make([]*pb.Container, 0, len(containers))
-	for _, container := range containers {
+func (c *Client) Concurrency() int {
+	return c.config.concurrency
+}
+
+func asPbEnv(container Container) []*pb.EnvVar {
+	result := make([]*pb.EnvVar, 0, len(container.Env)+len(container.SecretEnv))
+	for _, key := range maps.SortedKeys(container.Env) {
+		result = append(result, &pb.EnvVar{
+			Name:   key,
+			Values: &pb.EnvVar_Value{Value: container.Env[key]},
+		})
+	}
+
+	for _, key := range maps.SortedKeys(container.SecretEnv) {
+		ref := container.SecretEnv[key]
+		result = append(result, &pb.EnvVar{
+			Name: key,
+			Values: &pb.EnvVar_ValueSource{
+				ValueSource: &pb.EnvVarSource{
+					SecretKeyRef: &pb.SecretKeySelector{
+						Secret:  ref.Secret,
+						Version: ref.version(),
+					},
+				},
+			},
+		})
+	}
+
+	return result
+}
+
+func asPbLimits(resources Resources) map[string]string {
+	limits := make(map[string]string)
+	if resources.CPU != "" {
+		limits["cpu"] = resources.CPU
+	}
+	if resources.Memory != "" {
+		limits["memory"] = resources.Memory
+	}
+	return limits
+}
+
+func asPbPorts(ports []Port) []*pb.ContainerPort {
+	result := make([]*pb.ContainerPort, 0, len(ports))
+	for _, port := range ports {
+		result = append(result, &pb.ContainerPort{
+			Name:          port.Name,
+			ContainerPort: int32(port.Number),
+		})
+	}
+	return result
+}
+
+func asPbContainers(revision *Revision) []*pb.Container {
+	result := make([]*pb.Container, 0, len(revision.Containers))
+	for _, container := range revision.Containers {
+		var command []string
+		if container.Command != "" {
+			command = []string{container.Command}
+		}
+
 		result = append(result, &pb.Container{
-			Name:  container.Name,
-			Image: container.Image,
+			Name:    container.Name,
+			Image:   container.Image,
+			Command: command,
+			Args:    container.Args,
+			Env:     asPbEnv(container),
+			Resources: &pb.ResourceRequirements{
+				Limits:          asPbLimits(container.Resources),
+				CpuIdle:         !container.Resources.CPUAlwaysAllocated,
+				StartupCpuBoost: revision.StartupCPUBoost,
+			},
+			Ports:         asPbPorts(container.Ports),
+			VolumeMounts:  asPbVolumeMounts(container.VolumeMounts),
+			StartupProbe:  asPbProbe(container.StartupProbe),
+			LivenessProbe: asPbProbe(container.LivenessProbe),
 		})
 	}
 	return result
 }
 
+func asPbRevisionTemplate(serviceName string, labels map[string]string, revision *Revision) *pb.RevisionTemplate {
+	var timeout *durationpb.Duration
+	if revision.Timeout > 0 {
+		timeout = durationpb.New(revision.Timeout)
+	}
+
+	return &pb.RevisionTemplate{
+		Revision: RevisionID(serviceName, revision.Name),
+		Labels:   labels,
+		Scaling: &pb.RevisionScaling{
+			MinInstanceCount: int32(revision.MinScale),
+			MaxInstanceCount: int32(revision.MaxScale),
+		},
+		VpcAccess:                     asPbVPCAccess(revision.VPCAccess),
+		Timeout:                       timeout,
+		ServiceAccount:                revision.ServiceAccount,
+		MaxInstanceRequestConcurrency: int32(revision.MaxConcurrency),
+		Containers:                    asPbContainers(revision),
+		Volumes:                       asPbVolumes(revision.Volumes),
+	}
+}
+
 func (c *Client) Create(ctx context.Context, name string, labels map[string]string, revision *Revision) (*pb.Service, error) {
 	req := &pb.CreateServiceRequest{
 		Parent:    c.Parent,
 		ServiceId: name,
 		Service: &pb.Service{
-			Description: "Managed by sblocks",
+			Description: managedDescription,
 			Labels:      labels,
-			Ingress:     pb.IngressTraffic_INGRESS_TRAFFIC_ALL,
-			Template: &pb.RevisionTemplate{
-				Revision:   fmt.Sprintf("%s-%s", name, revision.Name),
-				Labels:     labels,
-				Containers: asPbContainers(revision.Containers),
-			},
+			Ingress:     asPbIngress(revision.Ingress),
+			Template:    asPbRevisionTemplate(name, labels, revision),
 		},
 	}
 
 	log.Info(ctx, "start create service", zap.String("name", name))
-	op, err := c.services.CreateService(ctx, req)
+	var op *run.CreateServiceOperation
+	attempts := 0
+	err := c.call(ctx, "CreateService", func(ctx context.Context) error {
+		attempts++
+		var err error
+		op, err = c.services.CreateService(ctx, req)
+		return err
+	})
+	if status.Code(err) == codes.AlreadyExists && attempts > 1 {
+		// an earlier attempt can reach the server before failing, making the conflict
+		// our own create
+		return c.adoptCreated(ctx, name, labels, err)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	service, err := op.Wait(ctx)
+	var service *pb.Service
+	err = c.wait(ctx, Operation{Name: op.Name(), Method: "CreateService", Service: name}, func(ctx context.Context) error {
+		var err error
+		service, err = op.Wait(ctx)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -91,19 +228,24 @@ func (c *Client) List(ctx context.Context) ([]*pb.Service, error) {
 	}
 
 	var services []*pb.Service
-	it := c.services.ListServices(ctx, req)
+	err := c.call(ctx, "ListServices", func(ctx context.Context) error {
+		services = nil
+		it := c.services.ListServices(ctx, req)
+
+		for {
+			resp, err := it.Next()
+			if err == iterator.Done {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
 
-	for {
-		resp, err := it.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return nil, err
+			services = append(services, resp)
 		}
-
-		services = append(services, resp)
-
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return services, nil
@@ -115,62 +257,94 @@ func (c *Client) ListRevisions(ctx context.Context, serviceName string) ([]*pb.R
 	}
 
 	var revisions []*pb.Revision
-	it := c.revisions.ListRevisions(ctx, req)
+	err := c.call(ctx, "ListRevisions", func(ctx context.Context) error {
+		revisions = nil
+		it := c.revisions.ListRevisions(ctx, req)
+
+		for {
+			resp, err := it.Next()
+			if err == iterator.Done {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
 
-	for {
-		resp, err := it.Next()
-		if err == iterator.Done {
-			break
+			revisions = append(revisions, resp)
 		}
-		if err != nil {
-			return nil, err
-		}
-
-		revisions = append(revisions, resp)
-
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return revisions, nil
 }
 
-func (c *Client) AllowPublicAccess(ctx context.Context, serviceName string) error {
-	req := &iampb.SetIamPolicyRequest{
+func (c *Client) GetIamPolicy(ctx context.Context, serviceName string) (*iampb.Policy, error) {
+	req := &iampb.GetIamPolicyRequest{
 		Resource: fmt.Sprintf("%s/services/%s", c.Parent, serviceName),
-		Policy: &iampb.Policy{
-			Bindings: []*iampb.Binding{
-				{Role: "roles/run.invoker", Members: []string{"allUsers"}},
-			},
-		},
 	}
 
-	_, err := c.services.SetIamPolicy(ctx, req)
-	if err != nil {
+	var policy *iampb.Policy
+	err := c.call(ctx, "GetIamPolicy", func(ctx context.Context) error {
+		var err error
+		policy, err = c.services.GetIamPolicy(ctx, req)
 		return err
-	}
+	})
+	return policy, err
+}
 
-	return nil
+// UpdateIamPolicy reads the policy, applies update and writes it back with the etag that
+// was read. A concurrent change makes the write fail with ABORTED, which is retried from
+// the read.
+func (c *Client) UpdateIamPolicy(ctx context.Context, serviceName string, update func(*iampb.Policy) bool) error {
+	resource := fmt.Sprintf("%s/services/%s", c.Parent, serviceName)
+
+	return c.call(ctx, "SetIamPolicy", func(ctx context.Context) error {
+		policy, err := c.services.GetIamPolicy(ctx, &iampb.GetIamPolicyRequest{Resource: resource})
+		if err != nil {
+			return err
+		}
+
+		if !update(policy) {
+			return nil
+		}
+
+		_, err = c.services.SetIamPolicy(ctx, &iampb.SetIamPolicyRequest{
+			Resource: resource,
+			Policy:   policy,
+		})
+		return err
+	})
 }
 
-func (c *Client) Update(ctx context.Context, serviceName string, labels map[string]string, revision *Revision) error {
+func (c *Client) Update(ctx context.Context, serviceName string, labels map[string]string, revision *Revision, traffic []TrafficTarget) error {
 	req := &pb.UpdateServiceRequest{
 		Service: &runpb.Service{
-			Name:   fmt.Sprintf("%s/services/%s", c.Parent, serviceName),
-			Labels: labels,
-			Template: &pb.RevisionTemplate{
-				Revision:   fmt.Sprintf("%s-%s", serviceName, revision.Name),
-				Labels:     labels,
-				Containers: asPbContainers(revision.Containers),
-			},
+			Name:        fmt.Sprintf("%s/services/%s", c.Parent, serviceName),
+			Description: managedDescription,
+			Labels:      labels,
+			Ingress:     asPbIngress(revision.Ingress),
+			Template:    asPbRevisionTemplate(serviceName, labels, revision),
+			Traffic:     asPbTraffic(traffic),
 		},
 	}
 
 	log.Info(ctx, "start update service", zap.String("name", serviceName))
-	op, err := c.services.UpdateService(ctx, req)
+	var op *run.UpdateServiceOperation
+	err := c.call(ctx, "UpdateService", func(ctx context.Context) error {
+		var err error
+		op, err = c.services.UpdateService(ctx, req)
+		return err
+	})
 	if err != nil {
 		return err
 	}
 
-	_, err = op.Wait(ctx)
+	err = c.wait(ctx, Operation{Name: op.Name(), Method: "UpdateService", Service: serviceName}, func(ctx context.Context) error {
+		_, err := op.Wait(ctx)
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -179,38 +353,213 @@ func (c *Client) Update(ctx context.Context, serviceName string, labels map[stri
 	return nil
 }
 
-func (c *Client) DeleteAll(ctx context.Context) error {
-	services, err := c.List(ctx)
+func asPbTraffic(targets []TrafficTarget) []*pb.TrafficTarget {
+	result := make([]*pb.TrafficTarget, 0, len(targets))
+	for _, target := range targets {
+		if target.Latest {
+			result = append(result, &pb.TrafficTarget{
+				Type:    pb.TrafficTargetAllocationType_TRAFFIC_TARGET_ALLOCATION_TYPE_LATEST,
+				Percent: target.Percent,
+				Tag:     target.Tag,
+			})
+			continue
+		}
+
+		result = append(result, &pb.TrafficTarget{
+			Type:     pb.TrafficTargetAllocationType_TRAFFIC_TARGET_ALLOCATION_TYPE_REVISION,
+			Revision: target.Revision,
+			Percent:  target.Percent,
+			Tag:      target.Tag,
+		})
+	}
+	return result
+}
+
+func (c *Client) getService(ctx context.Context, serviceName string) (*pb.Service, error) {
+	var service *pb.Service
+	err := c.call(ctx, "GetService", func(ctx context.Context) error {
+		var err error
+		service, err = c.services.GetService(ctx, &pb.GetServiceRequest{
+			Name: fmt.Sprintf("%s/services/%s", c.Parent, serviceName),
+		})
+		return err
+	})
+	return service, err
+}
+
+// adoptCreated waits for a service that a retried create found already existing, as
+// long as it carries the description and labels that the create sent. Otherwise the
+// conflict is returned.
+func (c *Client) adoptCreated(ctx context.Context, name string, labels map[string]string, conflict error) (*pb.Service, error) {
+	service, err := c.getService(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if service.Description != managedDescription || len(service.Labels) != len(labels) {
+		return nil, conflict
+	}
+	for key, value := range labels {
+		if service.Labels[key] != value {
+			return nil, conflict
+		}
+	}
+
+	log.Warn(ctx, "adopt service created by an earlier attempt", zap.String("name", name))
+
+	// the create operation is unknown, so poll the service until it settles
+	waitCtx, cancel := withTimeout(ctx, c.config.operationTimeout)
+	defer cancel()
+
+	for service.Reconciling {
+		select {
+		case <-waitCtx.Done():
+			return nil, waitCtx.Err()
+		case <-time.After(c.config.initialBackoff):
+		}
+
+		service, err = c.getService(waitCtx, name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if condition := service.TerminalCondition; condition != nil && condition.State == pb.Condition_CONDITION_FAILED {
+		return nil, fmt.Errorf("create service %s failed: %s", name, condition.Message)
+	}
+
+	log.Info(ctx, "finished create service", zap.String("name", name))
+	return service, nil
+}
+
+func (c *Client) SetTraffic(ctx context.Context, serviceName string, targets []TrafficTarget) error {
+	service, err := c.getService(ctx, serviceName)
 	if err != nil {
 		return err
 	}
 
-	group, ctx := errgroup.WithContext(ctx)
+	service.Traffic = asPbTraffic(targets)
 
-	for _, service := range services {
-		name := service.Name
-		shortName := strings.SplitN(name, "/", 6)[5]
+	log.Info(ctx, "start set traffic", zap.String("name", serviceName))
+	var op *run.UpdateServiceOperation
+	err = c.call(ctx, "UpdateService", func(ctx context.Context) error {
+		var err error
+		op, err = c.services.UpdateService(ctx, &pb.UpdateServiceRequest{Service: service})
+		return err
+	})
+	if err != nil {
+		return err
+	}
 
-		group.Go(func() error {
-			req := &pb.DeleteServiceRequest{
-				Name: name,
-			}
+	err = c.wait(ctx, Operation{Name: op.Name(), Method: "UpdateService", Service: serviceName}, func(ctx context.Context) error {
+		_, err := op.Wait(ctx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
 
-			log.Info(ctx, "start delete service", zap.String("name", shortName))
-			op, err := c.services.DeleteService(ctx, req)
-			if err != nil {
-				return err
-			}
+	log.Info(ctx, "finished set traffic", zap.String("name", serviceName))
+	return nil
+}
 
-			_, err = op.Wait(ctx)
-			if err != nil {
-				return err
-			}
+func (c *Client) SetLabels(ctx context.Context, serviceName string, labels map[string]string) error {
+	service, err := c.getService(ctx, serviceName)
+	if err != nil {
+		return err
+	}
 
-			log.Info(ctx, "finished delete service", zap.String("name", shortName))
-			return nil
-		})
+	service.Labels = labels
+
+	log.Info(ctx, "start set labels", zap.String("name", serviceName))
+	var op *run.UpdateServiceOperation
+	err = c.call(ctx, "UpdateService", func(ctx context.Context) error {
+		var err error
+		op, err = c.services.UpdateService(ctx, &pb.UpdateServiceRequest{Service: service})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = c.wait(ctx, Operation{Name: op.Name(), Method: "UpdateService", Service: serviceName}, func(ctx context.Context) error {
+		_, err := op.Wait(ctx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info(ctx, "finished set labels", zap.String("name", serviceName))
+	return nil
+}
+
+func (c *Client) Delete(ctx context.Context, serviceName string) error {
+	req := &pb.DeleteServiceRequest{
+		Name: fmt.Sprintf("%s/services/%s", c.Parent, serviceName),
+	}
+
+	log.Info(ctx, "start delete service", zap.String("name", serviceName))
+	var op *run.DeleteServiceOperation
+	attempts := 0
+	err := c.call(ctx, "DeleteService", func(ctx context.Context) error {
+		attempts++
+		var err error
+		op, err = c.services.DeleteService(ctx, req)
+		return err
+	})
+	if status.Code(err) == codes.NotFound && attempts > 1 {
+		// an earlier attempt reached the server before failing and deleted the service
+		log.Info(ctx, "finished delete service", zap.String("name", serviceName))
+		return nil
+	}
+	if err != nil {
+		return err
 	}
 
-	return group.Wait()
+	err = c.wait(ctx, Operation{Name: op.Name(), Method: "DeleteService", Service: serviceName}, func(ctx context.Context) error {
+		_, err := op.Wait(ctx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info(ctx, "finished delete service", zap.String("name", serviceName))
+	return nil
+}
+
+func (c *Client) DeleteRevision(ctx context.Context, serviceName, revisionID string) error {
+	req := &pb.DeleteRevisionRequest{
+		Name: fmt.Sprintf("%s/services/%s/revisions/%s", c.Parent, serviceName, revisionID),
+	}
+
+	log.Info(ctx, "start delete revision", zap.String("service", serviceName), zap.String("revision", revisionID))
+	var op *run.DeleteRevisionOperation
+	attempts := 0
+	err := c.call(ctx, "DeleteRevision", func(ctx context.Context) error {
+		attempts++
+		var err error
+		op, err = c.revisions.DeleteRevision(ctx, req)
+		return err
+	})
+	if status.Code(err) == codes.NotFound && attempts > 1 {
+		// an earlier attempt reached the server before failing and deleted the revision
+		log.Info(ctx, "finished delete revision", zap.String("service", serviceName), zap.String("revision", revisionID))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	err = c.wait(ctx, Operation{Name: op.Name(), Method: "DeleteRevision", Service: serviceName, Revision: revisionID}, func(ctx context.Context) error {
+		_, err := op.Wait(ctx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info(ctx, "finished delete revision", zap.String("service", serviceName), zap.String("revision", revisionID))
+	return nil
 }
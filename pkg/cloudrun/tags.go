@@ -0,0 +1,96 @@
+package cloudrun
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/angelini/sblocks/internal/log"
+	"github.com/angelini/sblocks/internal/maps"
+	"go.uber.org/zap"
+)
+
+var tagPattern = regexp.MustCompile(`^[a-z][a-z0-9-]{0,45}$`)
+
+func validateTag(tag string) error {
+	if !tagPattern.MatchString(tag) {
+		return fmt.Errorf("invalid traffic tag %q, expected lowercase letters, digits and dashes", tag)
+	}
+	return nil
+}
+
+// TagRevision points a traffic tag at a revision on every service of the block without
+// changing how traffic is split. A tag already in use is moved to the revision.
+func (sb *ServiceBlock) TagRevision(ctx context.Context, client Backend, revisionName, tag string) error {
+	err := validateTag(tag)
+	if err != nil {
+		return err
+	}
+
+	for _, service := range maps.SortedValues(sb.services) {
+		if !service.hasRevision(revisionName) {
+			return fmt.Errorf("revision %s not found on service %s", revisionName, service.name)
+		}
+	}
+
+	err = sb.setTags(ctx, client, func(service *ServiceInstance, tags map[string]string) {
+		tags[tag] = RevisionID(service.name, revisionName)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info(ctx, "tagged revision", zap.String("block", sb.name), zap.String("revision", revisionName), zap.String("tag", tag))
+	return nil
+}
+
+func (sb *ServiceBlock) RemoveTag(ctx context.Context, client Backend, tag string) error {
+	err := sb.setTags(ctx, client, func(_ *ServiceInstance, tags map[string]string) {
+		delete(tags, tag)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info(ctx, "removed tag", zap.String("block", sb.name), zap.String("tag", tag))
+	return nil
+}
+
+func (sb *ServiceBlock) setTags(ctx context.Context, client Backend, edit func(*ServiceInstance, map[string]string)) error {
+	group, groupCtx := newGroup(ctx, client)
+
+	for _, service := range sb.services {
+		service := service
+
+		tags := service.tagRevisions()
+		edit(service, tags)
+
+		targets := []TrafficTarget{{Latest: true, Percent: 100}}
+		if !service.traffic.latest {
+			targets = service.currentTraffic()
+		}
+		targets = withTags(targets, tags)
+
+		group.Go(func() error {
+			return client.SetTraffic(groupCtx, service.name, targets)
+		})
+	}
+
+	err := group.Wait()
+	if err != nil {
+		return err
+	}
+
+	return sb.Refresh(ctx, client)
+}
+
+// TagURIs returns the URL of a tag on each service of the block.
+func (sb *ServiceBlock) TagURIs(tag string) map[string]string {
+	uris := make(map[string]string, len(sb.services))
+	for name, service := range sb.services {
+		if target, found := service.traffic.tags[tag]; found {
+			uris[name] = target.URI
+		}
+	}
+	return uris
+}
@@ -0,0 +1,142 @@
+package cloudrun
+
+import (
+	"fmt"
+	"strings"
+
+	pb "cloud.google.com/go/run/apiv2/runpb"
+	"github.com/angelini/sblocks/internal/maps"
+)
+
+type VolumeType string
+
+const (
+	InMemoryVolume VolumeType = "memory"
+	SecretVolume   VolumeType = "secret"
+)
+
+type Volume struct {
+	Name      string
+	Type      VolumeType
+	SizeLimit string
+	Secret    string
+	Items     map[string]string
+}
+
+type VolumeMount struct {
+	Volume string
+	Path   string
+}
+
+func VolumeDefinition(volume *pb.Volume) Volume {
+	result := Volume{
+		Name: volume.Name,
+	}
+
+	switch source := volume.VolumeType.(type) {
+	case *pb.Volume_EmptyDir:
+		result.Type = InMemoryVolume
+		result.SizeLimit = source.EmptyDir.SizeLimit
+	case *pb.Volume_Secret:
+		result.Type = SecretVolume
+		result.Secret = source.Secret.Secret
+		result.Items = make(map[string]string, len(source.Secret.Items))
+		for _, item := range source.Secret.Items {
+			result.Items[item.Path] = item.Version
+		}
+	}
+
+	return result
+}
+
+func asPbVolumes(volumes map[string]Volume) []*pb.Volume {
+	result := make([]*pb.Volume, 0, len(volumes))
+	for _, volume := range maps.SortedValues(volumes) {
+		pbVolume := &pb.Volume{
+			Name: volume.Name,
+		}
+
+		switch volume.Type {
+		case InMemoryVolume:
+			pbVolume.VolumeType = &pb.Volume_EmptyDir{
+				EmptyDir: &pb.EmptyDirVolumeSource{
+					Medium:    pb.EmptyDirVolumeSource_MEMORY,
+					SizeLimit: volume.SizeLimit,
+				},
+			}
+		case SecretVolume:
+			items := make([]*pb.VersionToPath, 0, len(volume.Items))
+			for _, path := range maps.SortedKeys(volume.Items) {
+				items = append(items, &pb.VersionToPath{
+					Path:    path,
+					Version: volume.Items[path],
+				})
+			}
+
+			pbVolume.VolumeType = &pb.Volume_Secret{
+				Secret: &pb.SecretVolumeSource{
+					Secret: volume.Secret,
+					Items:  items,
+				},
+			}
+		}
+
+		result = append(result, pbVolume)
+	}
+	return result
+}
+
+func asPbVolumeMounts(mounts []VolumeMount) []*pb.VolumeMount {
+	result := make([]*pb.VolumeMount, 0, len(mounts))
+	for _, mount := range mounts {
+		result = append(result, &pb.VolumeMount{
+			Name:      mount.Volume,
+			MountPath: mount.Path,
+		})
+	}
+	return result
+}
+
+func (v *Volume) validate() error {
+	switch v.Type {
+	case InMemoryVolume:
+		if v.SizeLimit != "" {
+			if _, err := parseMemoryBytes(v.SizeLimit); err != nil {
+				return fmt.Errorf("invalid size limit %q: %w", v.SizeLimit, err)
+			}
+		}
+	case SecretVolume:
+		if v.Secret == "" {
+			return fmt.Errorf("secret volume requires a secret name")
+		}
+	default:
+		return fmt.Errorf("unsupported volume type %q", v.Type)
+	}
+
+	return nil
+}
+
+func (v *Volume) String() string {
+	switch v.Type {
+	case InMemoryVolume:
+		if v.SizeLimit == "" {
+			return fmt.Sprintf("%s (memory)", v.Name)
+		}
+		return fmt.Sprintf("%s (memory, limit=%s)", v.Name, v.SizeLimit)
+	case SecretVolume:
+		return fmt.Sprintf("%s (secret=%s)", v.Name, v.Secret)
+	}
+	return v.Name
+}
+
+func (m *VolumeMount) validate(volumes map[string]Volume) error {
+	if _, found := volumes[m.Volume]; !found {
+		return fmt.Errorf("mount references unknown volume %q", m.Volume)
+	}
+
+	if !strings.HasPrefix(m.Path, "/") {
+		return fmt.Errorf("mount path %q must be absolute", m.Path)
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package cloudrun
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/angelini/sblocks/internal/maps"
+)
+
+type EventType string
+
+const (
+	ServiceReadyEvent        EventType = "service-ready"
+	ServiceNotReadyEvent     EventType = "service-not-ready"
+	ServiceFailedEvent       EventType = "service-failed"
+	ReconcilingStartedEvent  EventType = "reconciling-started"
+	ReconcilingFinishedEvent EventType = "reconciling-finished"
+	RevisionCreatedEvent     EventType = "revision-created"
+	RevisionDeletedEvent     EventType = "revision-deleted"
+	TrafficChangedEvent      EventType = "traffic-changed"
+	WatchErrorEvent          EventType = "watch-error"
+)
+
+type Event struct {
+	Type     EventType
+	Time     time.Time
+	Block    string
+	Service  string
+	Revision string
+	Traffic  map[string]int
+	Err      error
+}
+
+func (e *Event) String() string {
+	target := e.Block
+	if e.Service != "" {
+		target = e.Service
+	}
+	if e.Revision != "" {
+		target = fmt.Sprintf("%s/%s", target, e.Revision)
+	}
+
+	result := fmt.Sprintf("%s %s %s", e.Time.Format("15:04:05"), e.Type, target)
+	switch e.Type {
+	case TrafficChangedEvent:
+		result += " " + formatSplit(e.Traffic)
+	case WatchErrorEvent:
+		result += fmt.Sprintf(": %v", e.Err)
+	}
+	return result
+}
+
+type serviceSnapshot struct {
+	state     ServiceState
+	revisions map[string]RevisionState
+	traffic   map[string]int
+}
+
+func (sb *ServiceBlock) snapshot() map[string]serviceSnapshot {
+	result := make(map[string]serviceSnapshot, len(sb.services))
+	for name, service := range sb.services {
+		revisions := make(map[string]RevisionState, len(service.revisions))
+		for _, revision := range service.revisions {
+			revisions[revision.definition.Name] = revision.state
+		}
+
+		result[name] = serviceSnapshot{
+			state:     service.state,
+			revisions: revisions,
+			traffic:   service.trafficSplit(),
+		}
+	}
+	return result
+}
+
+func (sb *ServiceBlock) diff(previous, current map[string]serviceSnapshot, now time.Time) []Event {
+	events := []Event{}
+	event := func(eventType EventType, service, revision string) Event {
+		return Event{Type: eventType, Time: now, Block: sb.name, Service: service, Revision: revision}
+	}
+
+	for _, name := range maps.SortedKeys(current) {
+		after := current[name]
+		before, found := previous[name]
+		if !found {
+			before = serviceSnapshot{state: NewServiceState(), revisions: map[string]RevisionState{}}
+		}
+
+		if !before.state.isReady && after.state.isReady {
+			events = append(events, event(ServiceReadyEvent, name, ""))
+		}
+		if before.state.isReady && !after.state.isReady {
+			events = append(events, event(ServiceNotReadyEvent, name, ""))
+		}
+		if !before.state.isFailed && after.state.isFailed {
+			events = append(events, event(ServiceFailedEvent, name, ""))
+		}
+		if !before.state.isReconciling && after.state.isReconciling {
+			events = append(events, event(ReconcilingStartedEvent, name, ""))
+		}
+		if before.state.isReconciling && !after.state.isReconciling {
+			events = append(events, event(ReconcilingFinishedEvent, name, ""))
+		}
+
+		for _, revision := range maps.SortedKeys(after.revisions) {
+			if _, found := before.revisions[revision]; !found {
+				events = append(events, event(RevisionCreatedEvent, name, revision))
+			}
+		}
+		for _, revision := range maps.SortedKeys(before.revisions) {
+			if _, found := after.revisions[revision]; !found || (!before.revisions[revision].isDeleted && after.revisions[revision].isDeleted) {
+				events = append(events, event(RevisionDeletedEvent, name, revision))
+			}
+		}
+
+		if formatSplit(before.traffic) != formatSplit(after.traffic) {
+			changed := event(TrafficChangedEvent, name, "")
+			changed.Traffic = after.traffic
+			events = append(events, changed)
+		}
+	}
+
+	return events
+}
+
+// Watch polls the block every interval and sends an event for each service or revision
+// state change until ctx is done. The block is refreshed in place, so it should not be
+// used concurrently while watched.
+func (sb *ServiceBlock) Watch(ctx context.Context, client Backend, interval time.Duration) (<-chan Event, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid watch interval %s, must be positive", interval)
+	}
+
+	events := make(chan Event)
+
+	go func() {
+		defer close(events)
+
+		previous := sb.snapshot()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			batch := []Event{}
+			err := sb.Refresh(ctx, client)
+			if err != nil {
+				batch = append(batch, Event{Type: WatchErrorEvent, Time: time.Now(), Block: sb.name, Err: err})
+			} else {
+				current := sb.snapshot()
+				batch = sb.diff(previous, current, time.Now())
+				previous = current
+			}
+
+			for _, event := range batch {
+				select {
+				case <-ctx.Done():
+					return
+				case events <- event:
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+func formatSplit(split map[string]int) string {
+	result := ""
+	for idx, revision := range maps.SortedKeys(split) {
+		if idx != 0 {
+			result += ","
+		}
+		result += fmt.Sprintf("%s=%d", revision, split[revision])
+	}
+	return result
+}
@@ -2,20 +2,24 @@ package executor
 
 import (
 	"context"
+	"fmt"
 
 	pb "github.com/angelini/sblocks/internal/executorpb"
 	"github.com/angelini/sblocks/pkg/cloudrun"
 	clientv3 "go.etcd.io/etcd/client/v3"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 type ExecutorApi struct {
 	pb.UnimplementedExecutorServer
 
 	etcd     *clientv3.Client
-	cloudrun *cloudrun.Client
+	cloudrun cloudrun.Backend
 }
 
-func NewExecutorApi(etcd *clientv3.Client, cr *cloudrun.Client) *ExecutorApi {
+func NewExecutorApi(etcd *clientv3.Client, cr cloudrun.Backend) *ExecutorApi {
 	return &ExecutorApi{
 		etcd:     etcd,
 		cloudrun: cr,
@@ -31,12 +35,54 @@ func (a *ExecutorApi) GetService(ctx context.Context, req *pb.GetServiceRequest)
 	}, nil
 }
 
+func runtimeKey(runtime string) string {
+	return fmt.Sprintf("/sblocks/runtimes/%s", runtime)
+}
+
+// GetRuntimeInfo returns the containers and volumes last stored by UpdateRuntime, or
+// only the runtime name when it has not been updated yet.
 func (a *ExecutorApi) GetRuntimeInfo(ctx context.Context, req *pb.GetRuntimeInfoRequest) (*pb.GetRuntimeInfoResponse, error) {
+	resp, err := a.etcd.Get(ctx, runtimeKey(req.Runtime))
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "cannot load runtime %s: %v", req.Runtime, err)
+	}
+
+	if len(resp.Kvs) == 0 {
+		return &pb.GetRuntimeInfoResponse{
+			Runtime: req.Runtime,
+		}, nil
+	}
+
+	var stored pb.UpdateRuntimeRequest
+	err = proto.Unmarshal(resp.Kvs[0].Value, &stored)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot decode runtime %s: %v", req.Runtime, err)
+	}
+
 	return &pb.GetRuntimeInfoResponse{
-		Runtime: req.Runtime,
+		Runtime:   req.Runtime,
+		Container: stored.Container,
+		Volume:    stored.Volume,
 	}, nil
 }
 
 func (a *ExecutorApi) UpdateRuntime(ctx context.Context, req *pb.UpdateRuntimeRequest) (*pb.UpdateRuntimeResponse, error) {
+	revision := asRevision(req.Container, req.Volume)
+
+	err := revision.Validate()
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid runtime %s: %v", req.Runtime, err)
+	}
+
+	value, err := proto.Marshal(req)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot encode runtime %s: %v", req.Runtime, err)
+	}
+
+	_, err = a.etcd.Put(ctx, runtimeKey(req.Runtime), string(value))
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "cannot save runtime %s: %v", req.Runtime, err)
+	}
+
 	return &pb.UpdateRuntimeResponse{}, nil
 }
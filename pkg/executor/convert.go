@@ -0,0 +1,70 @@
+package executor
+
+import (
+	pb "github.com/angelini/sblocks/internal/executorpb"
+	"github.com/angelini/sblocks/pkg/cloudrun"
+)
+
+func asContainer(container *pb.Container) cloudrun.Container {
+	secretEnv := make(map[string]cloudrun.SecretRef, len(container.SecretEnv))
+	for key, ref := range container.SecretEnv {
+		secretEnv[key] = cloudrun.SecretRef{
+			Secret:  ref.Secret,
+			Version: ref.Version,
+		}
+	}
+
+	mounts := make([]cloudrun.VolumeMount, 0, len(container.VolumeMounts))
+	for _, mount := range container.VolumeMounts {
+		mounts = append(mounts, cloudrun.VolumeMount{
+			Volume: mount.Volume,
+			Path:   mount.Path,
+		})
+	}
+
+	return cloudrun.Container{
+		Name:         container.Name,
+		Image:        container.Image,
+		Command:      container.Command,
+		Args:         container.Args,
+		Env:          container.Env,
+		SecretEnv:    secretEnv,
+		VolumeMounts: mounts,
+	}
+}
+
+func asVolume(volume *pb.Volume) cloudrun.Volume {
+	// an unspecified type is left empty so that validation rejects the volume
+	var volumeType cloudrun.VolumeType
+	switch volume.Type {
+	case pb.VolumeType_IN_MEMORY:
+		volumeType = cloudrun.InMemoryVolume
+	case pb.VolumeType_SECRET:
+		volumeType = cloudrun.SecretVolume
+	}
+
+	return cloudrun.Volume{
+		Name:      volume.Name,
+		Type:      volumeType,
+		SizeLimit: volume.SizeLimit,
+		Secret:    volume.Secret,
+		Items:     volume.Items,
+	}
+}
+
+func asRevision(containers []*pb.Container, volumes []*pb.Volume) *cloudrun.Revision {
+	revision := &cloudrun.Revision{
+		Containers: make(map[string]cloudrun.Container, len(containers)),
+		Volumes:    make(map[string]cloudrun.Volume, len(volumes)),
+	}
+
+	for _, container := range containers {
+		revision.Containers[container.Name] = asContainer(container)
+	}
+
+	for _, volume := range volumes {
+		revision.Volumes[volume.Name] = asVolume(volume)
+	}
+
+	return revision
+}
@@ -13,7 +13,7 @@ import (
 	"google.golang.org/grpc"
 )
 
-func NewServer(ctx context.Context, etcd *clientv3.Client, cr *cloudrun.Client) *grpc.Server {
+func NewServer(ctx context.Context, etcd *clientv3.Client, cr cloudrun.Backend) *grpc.Server {
 	grpcServer := grpc.NewServer(
 		grpc.UnaryInterceptor(
 			grpc_middleware.ChainUnaryServer(
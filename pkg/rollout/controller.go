@@ -0,0 +1,288 @@
+package rollout
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/angelini/sblocks/internal/log"
+	"github.com/angelini/sblocks/internal/maps"
+	"github.com/angelini/sblocks/pkg/cloudrun"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+type Config struct {
+	Steps      []int
+	Interval   time.Duration
+	Checks     int
+	ProbePath  string
+	HTTPClient *http.Client
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Steps:      []int{5, 25, 50, 100},
+		Interval:   30 * time.Second,
+		Checks:     3,
+		ProbePath:  "/",
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (c *Config) validate() error {
+	if len(c.Steps) == 0 {
+		return fmt.Errorf("rollout requires at least one step")
+	}
+
+	previous := 0
+	for _, step := range c.Steps {
+		if step <= previous || step > 100 {
+			return fmt.Errorf("rollout steps must increase between 1 and 100, found %v", c.Steps)
+		}
+		previous = step
+	}
+
+	if previous != 100 {
+		return fmt.Errorf("last rollout step must be 100, found %d", previous)
+	}
+
+	return nil
+}
+
+type Controller struct {
+	client cloudrun.Backend
+	store  Store
+	config Config
+}
+
+func NewController(client cloudrun.Backend, store Store, config Config) *Controller {
+	return &Controller{
+		client: client,
+		store:  store,
+		config: config,
+	}
+}
+
+// Start stages the revision with no traffic and progressively shifts traffic to it,
+// restoring the previous split if any step fails its health checks.
+func (c *Controller) Start(ctx context.Context, environment string, block *cloudrun.ServiceBlock, revision *cloudrun.Revision) error {
+	err := c.config.validate()
+	if err != nil {
+		return err
+	}
+
+	existing, err := c.store.Load(ctx, environment, block.Name())
+	if err != nil {
+		return err
+	}
+
+	if existing != nil && existing.IsActive() {
+		return fmt.Errorf("rollout of revision %s is already in progress on block %s", existing.Revision.Name, block.Name())
+	}
+
+	previous, err := block.TrafficSplit()
+	if err != nil {
+		return err
+	}
+
+	if _, found := previous[revision.Name]; found {
+		return fmt.Errorf("revision %s is already receiving traffic", revision.Name)
+	}
+
+	state := &State{
+		Environment: environment,
+		Block:       block.Name(),
+		Revision:    revision,
+		Steps:       c.config.Steps,
+		Step:        0,
+		Previous:    previous,
+		Status:      StatusStaging,
+	}
+
+	err = c.store.Save(ctx, state)
+	if err != nil {
+		return err
+	}
+
+	return c.run(ctx, block, state)
+}
+
+// Resume continues an interrupted rollout from the last step persisted in the store.
+func (c *Controller) Resume(ctx context.Context, environment string, block *cloudrun.ServiceBlock) error {
+	state, err := c.store.Load(ctx, environment, block.Name())
+	if err != nil {
+		return err
+	}
+
+	if state == nil || !state.IsActive() {
+		return fmt.Errorf("no rollout in progress on block %s", block.Name())
+	}
+
+	log.Info(ctx, "resume rollout", zap.String("block", state.Block), zap.String("revision", state.Revision.Name), zap.Int("step", state.Step))
+	return c.run(ctx, block, state)
+}
+
+// Abort restores the traffic split recorded before an in-progress rollout started.
+func (c *Controller) Abort(ctx context.Context, environment string, block *cloudrun.ServiceBlock) error {
+	state, err := c.store.Load(ctx, environment, block.Name())
+	if err != nil {
+		return err
+	}
+
+	if state == nil || !state.IsActive() {
+		return fmt.Errorf("no rollout in progress on block %s", block.Name())
+	}
+
+	return c.rollback(ctx, block, state, "aborted")
+}
+
+func (c *Controller) run(ctx context.Context, block *cloudrun.ServiceBlock, state *State) error {
+	if state.Status == StatusStaging {
+		if !block.HasRevision(state.Revision.Name) {
+			err := block.StageRevision(ctx, c.client, state.Revision)
+			if err != nil {
+				return c.rollback(ctx, block, state, fmt.Sprintf("staging failed: %v", err))
+			}
+		}
+
+		state.Status = StatusShifting
+		err := c.store.Save(ctx, state)
+		if err != nil {
+			return err
+		}
+	}
+
+	for state.Step < len(state.Steps) {
+		percent := state.Steps[state.Step]
+		split := shiftSplit(state.Previous, state.Revision.Name, percent)
+
+		log.Info(ctx, "start rollout step", zap.String("block", state.Block), zap.Int("percent", percent))
+		err := block.SetTraffic(ctx, c.client, split)
+		if err != nil {
+			return c.rollback(ctx, block, state, fmt.Sprintf("step %d%% failed: %v", percent, err))
+		}
+
+		err = c.verify(ctx, block, state.Revision.Name)
+		if err != nil {
+			return c.rollback(ctx, block, state, fmt.Sprintf("step %d%% unhealthy: %v", percent, err))
+		}
+
+		state.Step += 1
+		err = c.store.Save(ctx, state)
+		if err != nil {
+			return err
+		}
+	}
+
+	state.Status = StatusCompleted
+	log.Info(ctx, "finished rollout", zap.String("block", state.Block), zap.String("revision", state.Revision.Name))
+	return c.store.Save(ctx, state)
+}
+
+func (c *Controller) verify(ctx context.Context, block *cloudrun.ServiceBlock, revision string) error {
+	for check := 0; check < c.config.Checks; check++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.config.Interval):
+		}
+
+		err := block.Refresh(ctx, c.client)
+		if err != nil {
+			return err
+		}
+
+		err = block.CheckReady(revision)
+		if err != nil {
+			return err
+		}
+
+		err = c.probe(ctx, block)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Controller) probe(ctx context.Context, block *cloudrun.ServiceBlock) error {
+	group, ctx := errgroup.WithContext(ctx)
+
+	for name, uri := range block.URIs() {
+		name, uri := name, uri
+		group.Go(func() error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri+c.config.ProbePath, nil)
+			if err != nil {
+				return err
+			}
+
+			resp, err := c.config.HTTPClient.Do(req)
+			if err != nil {
+				return fmt.Errorf("probe of service %s failed: %w", name, err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode >= http.StatusInternalServerError {
+				return fmt.Errorf("probe of service %s returned %d", name, resp.StatusCode)
+			}
+			return nil
+		})
+	}
+
+	return group.Wait()
+}
+
+func (c *Controller) rollback(ctx context.Context, block *cloudrun.ServiceBlock, state *State, reason string) error {
+	log.Warn(ctx, "rollback rollout", zap.String("block", state.Block), zap.String("reason", reason))
+
+	err := block.SetTraffic(ctx, c.client, state.Previous)
+	if err != nil {
+		return fmt.Errorf("rollback after %q failed: %w", reason, err)
+	}
+
+	state.Status = StatusRolledBack
+	state.Reason = reason
+	err = c.store.Save(ctx, state)
+	if err != nil {
+		return err
+	}
+
+	return fmt.Errorf("rollout of revision %s rolled back: %s", state.Revision.Name, reason)
+}
+
+// shiftSplit gives percent of the traffic to revision and scales the previous split
+// down to share the remainder, assigning any rounding leftover to the largest revision.
+func shiftSplit(previous map[string]int, revision string, percent int) map[string]int {
+	split := map[string]int{revision: percent}
+	remaining := 100 - percent
+	if remaining == 0 {
+		return split
+	}
+
+	assigned := 0
+	largest := ""
+	for _, name := range maps.SortedKeys(previous) {
+		share := previous[name] * remaining / 100
+		split[name] = share
+		assigned += share
+
+		if largest == "" || previous[name] > previous[largest] {
+			largest = name
+		}
+	}
+
+	if largest != "" {
+		split[largest] += remaining - assigned
+	}
+
+	for name, share := range split {
+		if share == 0 && name != revision {
+			delete(split, name)
+		}
+	}
+
+	return split
+}
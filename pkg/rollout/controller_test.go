@@ -0,0 +1,198 @@
+package rollout
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/angelini/sblocks/internal/log"
+	"github.com/angelini/sblocks/pkg/cloudrun"
+)
+
+func TestShiftSplit(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous map[string]int
+		percent  int
+		expected map[string]int
+	}{
+		{"first step", map[string]int{"1": 100}, 5, map[string]int{"1": 95, "new": 5}},
+		{"last step", map[string]int{"1": 100}, 100, map[string]int{"new": 100}},
+		{"even split", map[string]int{"1": 50, "2": 50}, 25, map[string]int{"1": 38, "2": 37, "new": 25}},
+		{"leftover to the largest", map[string]int{"1": 70, "2": 30}, 5, map[string]int{"1": 67, "2": 28, "new": 5}},
+		{"three revisions", map[string]int{"1": 34, "2": 33, "3": 33}, 10, map[string]int{"1": 32, "2": 29, "3": 29, "new": 10}},
+		{"drops empty shares", map[string]int{"1": 99, "2": 1}, 50, map[string]int{"1": 50, "new": 50}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			split := shiftSplit(test.previous, "new", test.percent)
+
+			total := 0
+			for _, percent := range split {
+				total += percent
+			}
+			if total != 100 {
+				t.Fatalf("expected the split to sum to 100, found %v", split)
+			}
+
+			if len(split) != len(test.expected) {
+				t.Fatalf("expected %v, found %v", test.expected, split)
+			}
+			for revision, percent := range test.expected {
+				if split[revision] != percent {
+					t.Fatalf("expected %v, found %v", test.expected, split)
+				}
+			}
+		})
+	}
+}
+
+type memoryStore struct {
+	states    map[string]*State
+	rollbacks map[string]*Rollback
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		states:    make(map[string]*State),
+		rollbacks: make(map[string]*Rollback),
+	}
+}
+
+func (s *memoryStore) Load(ctx context.Context, environment, block string) (*State, error) {
+	return s.states[environment+"/"+block], nil
+}
+
+func (s *memoryStore) Save(ctx context.Context, state *State) error {
+	saved := *state
+	s.states[state.Environment+"/"+state.Block] = &saved
+	return nil
+}
+
+func (s *memoryStore) LoadRollback(ctx context.Context, environment, block string) (*Rollback, error) {
+	return s.rollbacks[environment+"/"+block], nil
+}
+
+func (s *memoryStore) SaveRollback(ctx context.Context, rollback *Rollback) error {
+	saved := *rollback
+	s.rollbacks[rollback.Environment+"/"+rollback.Block] = &saved
+	return nil
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func testController(t *testing.T, client cloudrun.Backend, store Store) (context.Context, *Controller) {
+	ctx, err := log.Init(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the fake service URLs do not resolve, so every probe is answered locally
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+	})}
+
+	return ctx, NewController(client, store, Config{
+		Steps:      []int{50, 100},
+		Interval:   time.Millisecond,
+		Checks:     1,
+		ProbePath:  "/",
+		HTTPClient: httpClient,
+	})
+}
+
+func testRevision(name string) *cloudrun.Revision {
+	return &cloudrun.Revision{
+		Name:    name,
+		Timeout: time.Minute,
+		Containers: map[string]cloudrun.Container{
+			"app": {Name: "app", Image: "gcr.io/test/app:" + name},
+		},
+	}
+}
+
+func TestRolloutCompletes(t *testing.T) {
+	client := cloudrun.NewFakeBackend("project", "region")
+	store := newMemoryStore()
+	ctx, controller := testController(t, client, store)
+
+	block, err := cloudrun.CreateNamedServiceBlock(ctx, client, "api", false, 2, map[string]string{cloudrun.EnvironmentLabel: "test"}, testRevision("1"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = controller.Start(ctx, "test", block, testRevision("2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	split, err := block.TrafficSplit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(split) != 1 || split["2"] != 100 {
+		t.Fatalf("expected all traffic on revision 2, found %v", split)
+	}
+	if state := store.states["test/api"]; state.Status != StatusCompleted {
+		t.Fatalf("expected the rollout to complete, found %s", state.Status)
+	}
+}
+
+func TestRolloutRestoresPreviousSplit(t *testing.T) {
+	client := cloudrun.NewFakeBackend("project", "region")
+	store := newMemoryStore()
+	ctx, controller := testController(t, client, store)
+
+	block, err := cloudrun.CreateNamedServiceBlock(ctx, client, "api", false, 2, map[string]string{cloudrun.EnvironmentLabel: "test"}, testRevision("1"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = block.StageRevision(ctx, client, testRevision("2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = block.SetTraffic(ctx, client, map[string]int{"1": 70, "2": 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// services stay reconciling after every change, so CheckReady fails the first step
+	client.ReconcilePolls = 3
+
+	err = controller.Start(ctx, "test", block, testRevision("3"))
+	if err == nil || !strings.Contains(err.Error(), "unhealthy") {
+		t.Fatalf("expected the rollout to be rolled back as unhealthy, found %v", err)
+	}
+	if !strings.Contains(err.Error(), "reconciling") {
+		t.Fatalf("expected CheckReady to fail on a reconciling service, found %v", err)
+	}
+
+	state := store.states["test/api"]
+	if state.Status != StatusRolledBack {
+		t.Fatalf("expected the rollout to be rolled back, found %s", state.Status)
+	}
+
+	client.ReconcilePolls = 0
+	for attempt := 0; attempt < 5; attempt++ {
+		err = block.Refresh(ctx, client)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	split, err := block.TrafficSplit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(split) != 2 || split["1"] != 70 || split["2"] != 30 {
+		t.Fatalf("expected the previous 1=70,2=30 split to be restored, found %v", split)
+	}
+}
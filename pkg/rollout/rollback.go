@@ -0,0 +1,136 @@
+package rollout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/angelini/sblocks/internal/log"
+	"github.com/angelini/sblocks/internal/maps"
+	"github.com/angelini/sblocks/pkg/cloudrun"
+	"go.uber.org/zap"
+)
+
+// PreviousRevision can be passed to Rollback instead of a revision name to select the
+// revision deployed before the one serving traffic.
+const PreviousRevision = "previous"
+
+// Rollback records the traffic of every service before a rollback so that it can be undone.
+type Rollback struct {
+	Environment string                    `json:"environment"`
+	Block       string                    `json:"block"`
+	Revision    string                    `json:"revision"`
+	Previous    map[string]map[string]int `json:"previous"`
+	Failed      map[string]string         `json:"failed,omitempty"`
+	Undone      bool                      `json:"undone"`
+	UpdatedAt   time.Time                 `json:"updated_at"`
+}
+
+// Rollback sends all traffic of every service in the block to an existing revision
+// without redeploying. The split it replaces is saved before any service changes.
+func (c *Controller) Rollback(ctx context.Context, environment string, block *cloudrun.ServiceBlock, revision string) (*Rollback, error) {
+	state, err := c.store.Load(ctx, environment, block.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	if state != nil && state.IsActive() {
+		return nil, fmt.Errorf("rollout of revision %s is in progress on block %s, abort it first", state.Revision.Name, block.Name())
+	}
+
+	err = block.Refresh(ctx, c.client)
+	if err != nil {
+		return nil, err
+	}
+
+	if revision == PreviousRevision {
+		revision, err = block.PreviousRevision()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = block.CheckRevision(revision)
+	if err != nil {
+		return nil, err
+	}
+
+	rollback := &Rollback{
+		Environment: environment,
+		Block:       block.Name(),
+		Revision:    revision,
+		Previous:    block.ServiceTraffic(),
+	}
+
+	err = c.store.SaveRollback(ctx, rollback)
+	if err != nil {
+		return nil, err
+	}
+
+	splits := make(map[string]map[string]int, len(rollback.Previous))
+	for _, serviceName := range maps.SortedKeys(rollback.Previous) {
+		splits[serviceName] = map[string]int{revision: 100}
+	}
+
+	log.Info(ctx, "start rollback", zap.String("block", block.Name()), zap.String("revision", revision))
+	err = c.applyRollback(ctx, block, rollback, splits)
+	if err != nil {
+		return rollback, err
+	}
+
+	log.Info(ctx, "finished rollback", zap.String("block", block.Name()), zap.String("revision", revision))
+	return rollback, nil
+}
+
+// UndoRollback restores the traffic recorded by the last rollback of the block.
+func (c *Controller) UndoRollback(ctx context.Context, environment string, block *cloudrun.ServiceBlock) (*Rollback, error) {
+	rollback, err := c.store.LoadRollback(ctx, environment, block.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	if rollback == nil || rollback.Undone {
+		return nil, fmt.Errorf("no rollback to undo on block %s", block.Name())
+	}
+
+	err = block.Refresh(ctx, c.client)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info(ctx, "start undo rollback", zap.String("block", block.Name()), zap.String("revision", rollback.Revision))
+	err = c.applyRollback(ctx, block, rollback, rollback.Previous)
+	if err != nil {
+		return rollback, err
+	}
+
+	rollback.Undone = true
+	err = c.store.SaveRollback(ctx, rollback)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info(ctx, "finished undo rollback", zap.String("block", block.Name()))
+	return rollback, nil
+}
+
+func (c *Controller) applyRollback(ctx context.Context, block *cloudrun.ServiceBlock, rollback *Rollback, splits map[string]map[string]int) error {
+	err := block.SetServiceTraffic(ctx, c.client, splits)
+
+	rollback.Failed = nil
+	var trafficErr *cloudrun.TrafficError
+	if errors.As(err, &trafficErr) {
+		rollback.Failed = make(map[string]string, len(trafficErr.Failed))
+		for serviceName, failure := range trafficErr.Failed {
+			rollback.Failed[serviceName] = failure.Error()
+		}
+	}
+
+	saveErr := c.store.SaveRollback(ctx, rollback)
+	if saveErr != nil {
+		log.Error(ctx, "failed to save rollback", zap.String("block", rollback.Block), zap.Error(saveErr))
+	}
+
+	return err
+}
@@ -0,0 +1,135 @@
+package rollout
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/angelini/sblocks/pkg/cloudrun"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+type Status string
+
+const (
+	StatusStaging    Status = "staging"
+	StatusShifting   Status = "shifting"
+	StatusCompleted  Status = "completed"
+	StatusRolledBack Status = "rolled-back"
+)
+
+type State struct {
+	Environment string             `json:"environment"`
+	Block       string             `json:"block"`
+	Revision    *cloudrun.Revision `json:"revision"`
+	Steps       []int              `json:"steps"`
+	Step        int                `json:"step"`
+	Previous    map[string]int     `json:"previous"`
+	Status      Status             `json:"status"`
+	Reason      string             `json:"reason,omitempty"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+func (s *State) IsActive() bool {
+	return s.Status == StatusStaging || s.Status == StatusShifting
+}
+
+type Store interface {
+	Load(ctx context.Context, environment, block string) (*State, error)
+	Save(ctx context.Context, state *State) error
+	LoadRollback(ctx context.Context, environment, block string) (*Rollback, error)
+	SaveRollback(ctx context.Context, rollback *Rollback) error
+}
+
+type EtcdStore struct {
+	etcd   *clientv3.Client
+	prefix string
+}
+
+func NewEtcdStore(etcd *clientv3.Client) *EtcdStore {
+	return &EtcdStore{
+		etcd:   etcd,
+		prefix: "/sblocks/rollouts",
+	}
+}
+
+func (s *EtcdStore) key(environment, block string) string {
+	return fmt.Sprintf("%s/%s/%s", s.prefix, environment, block)
+}
+
+// Load returns nil when no rollout has been recorded for the block.
+func (s *EtcdStore) Load(ctx context.Context, environment, block string) (*State, error) {
+	resp, err := s.etcd.Get(ctx, s.key(environment, block))
+	if err != nil {
+		return nil, fmt.Errorf("cannot load rollout state: %w", err)
+	}
+
+	if len(resp.Kvs) == 0 {
+		return nil, nil
+	}
+
+	var state State
+	err = json.Unmarshal(resp.Kvs[0].Value, &state)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode rollout state: %w", err)
+	}
+
+	return &state, nil
+}
+
+func (s *EtcdStore) Save(ctx context.Context, state *State) error {
+	state.UpdatedAt = time.Now()
+
+	value, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("cannot encode rollout state: %w", err)
+	}
+
+	_, err = s.etcd.Put(ctx, s.key(state.Environment, state.Block), string(value))
+	if err != nil {
+		return fmt.Errorf("cannot save rollout state: %w", err)
+	}
+
+	return nil
+}
+
+func (s *EtcdStore) rollbackKey(environment, block string) string {
+	return fmt.Sprintf("/sblocks/rollbacks/%s/%s", environment, block)
+}
+
+// LoadRollback returns nil when no rollback has been recorded for the block.
+func (s *EtcdStore) LoadRollback(ctx context.Context, environment, block string) (*Rollback, error) {
+	resp, err := s.etcd.Get(ctx, s.rollbackKey(environment, block))
+	if err != nil {
+		return nil, fmt.Errorf("cannot load rollback: %w", err)
+	}
+
+	if len(resp.Kvs) == 0 {
+		return nil, nil
+	}
+
+	var rollback Rollback
+	err = json.Unmarshal(resp.Kvs[0].Value, &rollback)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode rollback: %w", err)
+	}
+
+	return &rollback, nil
+}
+
+func (s *EtcdStore) SaveRollback(ctx context.Context, rollback *Rollback) error {
+	rollback.UpdatedAt = time.Now()
+
+	value, err := json.Marshal(rollback)
+	if err != nil {
+		return fmt.Errorf("cannot encode rollback: %w", err)
+	}
+
+	_, err = s.etcd.Put(ctx, s.rollbackKey(rollback.Environment, rollback.Block), string(value))
+	if err != nil {
+		return fmt.Errorf("cannot save rollback: %w", err)
+	}
+
+	return nil
+}
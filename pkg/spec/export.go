@@ -0,0 +1,158 @@
+package spec
+
+import (
+	"context"
+
+	"github.com/angelini/sblocks/internal/maps"
+	"github.com/angelini/sblocks/pkg/cloudrun"
+	"gopkg.in/yaml.v3"
+)
+
+// Export reads the blocks deployed in an environment into a spec that apply accepts
+// unchanged. Each block is described by the newest revision shared by all of its services.
+func Export(ctx context.Context, client cloudrun.Backend, environment string) (*EnvironmentSpec, error) {
+	blocks, err := cloudrun.LoadServiceBlocks(ctx, client, environment)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &EnvironmentSpec{
+		Name:   environment,
+		Blocks: make([]BlockSpec, 0, len(blocks)),
+	}
+
+	for _, name := range maps.SortedKeys(blocks) {
+		block := blocks[name]
+
+		revision, err := block.DesiredRevision()
+		if err != nil {
+			return nil, err
+		}
+
+		public, err := block.IsPublic(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		blockSpec := BlockSpec{
+			Name:     name,
+			Size:     block.Size(),
+			Public:   public,
+			Revision: RevisionSpecFrom(revision),
+		}
+		if labels := block.UserLabels(); len(labels) > 0 {
+			blockSpec.Labels = labels
+		}
+
+		result.Blocks = append(result.Blocks, blockSpec)
+	}
+
+	return result, nil
+}
+
+func (s *Spec) Marshal() ([]byte, error) {
+	return yaml.Marshal(s)
+}
+
+// RevisionSpecFrom converts a deployed revision back into its spec form.
+func RevisionSpecFrom(revision *cloudrun.Revision) RevisionSpec {
+	result := RevisionSpec{
+		Name:            revision.Name,
+		MinScale:        revision.MinScale,
+		MaxScale:        revision.MaxScale,
+		MaxConcurrency:  revision.MaxConcurrency,
+		Timeout:         revision.Timeout,
+		StartupCPUBoost: revision.StartupCPUBoost,
+		ServiceAccount:  revision.ServiceAccount,
+		Ingress:         string(revision.Ingress),
+	}
+
+	if revision.VPCAccess != nil {
+		result.VPCAccess = &VPCAccessSpec{
+			Connector:  revision.VPCAccess.Connector,
+			Network:    revision.VPCAccess.Network,
+			Subnetwork: revision.VPCAccess.Subnetwork,
+			Tags:       revision.VPCAccess.Tags,
+			Egress:     string(revision.VPCAccess.Egress),
+		}
+	}
+
+	for _, container := range maps.SortedValues(revision.Containers) {
+		result.Containers = append(result.Containers, containerSpecFrom(container))
+	}
+
+	for _, volume := range maps.SortedValues(revision.Volumes) {
+		result.Volumes = append(result.Volumes, VolumeSpec{
+			Name:      volume.Name,
+			Type:      string(volume.Type),
+			SizeLimit: volume.SizeLimit,
+			Secret:    volume.Secret,
+			Items:     volume.Items,
+		})
+	}
+
+	return result
+}
+
+func containerSpecFrom(container cloudrun.Container) ContainerSpec {
+	result := ContainerSpec{
+		Name:    container.Name,
+		Image:   container.Image,
+		Command: container.Command,
+		Args:    container.Args,
+		Resources: ResourcesSpec{
+			CPU:                container.Resources.CPU,
+			Memory:             container.Resources.Memory,
+			CPUAlwaysAllocated: container.Resources.CPUAlwaysAllocated,
+		},
+		StartupProbe:  probeSpecFrom(container.StartupProbe),
+		LivenessProbe: probeSpecFrom(container.LivenessProbe),
+	}
+
+	if len(container.Env) > 0 {
+		result.Env = container.Env
+	}
+
+	if len(container.SecretEnv) > 0 {
+		result.SecretEnv = make(map[string]SecretRefSpec, len(container.SecretEnv))
+		for key, ref := range container.SecretEnv {
+			result.SecretEnv[key] = SecretRefSpec{
+				Secret:  ref.Secret,
+				Version: ref.Version,
+			}
+		}
+	}
+
+	for _, port := range container.Ports {
+		result.Ports = append(result.Ports, PortSpec{
+			Name:   port.Name,
+			Number: port.Number,
+		})
+	}
+
+	for _, mount := range container.VolumeMounts {
+		result.VolumeMounts = append(result.VolumeMounts, VolumeMountSpec{
+			Volume: mount.Volume,
+			Path:   mount.Path,
+		})
+	}
+
+	return result
+}
+
+func probeSpecFrom(probe *cloudrun.Probe) *ProbeSpec {
+	if probe == nil {
+		return nil
+	}
+
+	return &ProbeSpec{
+		Type:             string(probe.Type),
+		Path:             probe.Path,
+		Port:             probe.Port,
+		Service:          probe.Service,
+		InitialDelay:     probe.InitialDelay,
+		Timeout:          probe.Timeout,
+		Period:           probe.Period,
+		FailureThreshold: probe.FailureThreshold,
+	}
+}
@@ -0,0 +1,86 @@
+package spec
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/angelini/sblocks/internal/log"
+	"github.com/angelini/sblocks/pkg/cloudrun"
+)
+
+func TestExportRoundTrip(t *testing.T) {
+	ctx, err := log.Init(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := cloudrun.NewFakeBackend("project", "region")
+
+	api := &cloudrun.Revision{
+		Name:           "1",
+		MaxScale:       10,
+		MaxConcurrency: 80,
+		Timeout:        time.Minute,
+		Ingress:        cloudrun.IngressAll,
+		Containers: map[string]cloudrun.Container{
+			"app": {
+				Name:      "app",
+				Image:     "gcr.io/test/api:1",
+				Args:      []string{"serve", "--verbose"},
+				Env:       map[string]string{"MODE": "production"},
+				Resources: cloudrun.Resources{CPU: "2", Memory: "1Gi"},
+				Ports:     []cloudrun.Port{{Number: 8080}},
+				VolumeMounts: []cloudrun.VolumeMount{
+					{Volume: "scratch", Path: "/scratch"},
+				},
+				StartupProbe: &cloudrun.Probe{Type: cloudrun.HTTPProbe, Path: "/ready", Port: 8080, Period: 10 * time.Second},
+			},
+		},
+		Volumes: map[string]cloudrun.Volume{
+			"scratch": {Name: "scratch", Type: cloudrun.InMemoryVolume, SizeLimit: "64Mi"},
+		},
+	}
+	_, err = cloudrun.CreateNamedServiceBlock(ctx, client, "api", true, 2, map[string]string{cloudrun.EnvironmentLabel: "test", "team": "core"}, api, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	worker := &cloudrun.Revision{
+		Name:    "1",
+		Timeout: time.Minute,
+		Containers: map[string]cloudrun.Container{
+			"worker": {Name: "worker", Image: "gcr.io/test/worker:1"},
+		},
+	}
+	_, err = cloudrun.CreateNamedServiceBlock(ctx, client, "worker", false, 1, map[string]string{cloudrun.EnvironmentLabel: "test"}, worker, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	environment, err := Export(ctx, client, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := (&Spec{Environments: []EnvironmentSpec{*environment}}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := Parse(content)
+	if err != nil {
+		t.Fatalf("parse exported spec: %v\n%s", err, content)
+	}
+
+	plan, err := NewPlan(ctx, client, parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Actions) != 0 {
+		for _, action := range plan.Actions {
+			t.Log(action.String())
+		}
+		t.Fatalf("expected an empty plan for the exported spec, found %d actions\n%s", len(plan.Actions), content)
+	}
+}
@@ -0,0 +1,225 @@
+package spec
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/angelini/sblocks/internal/log"
+	"github.com/angelini/sblocks/internal/maps"
+	"github.com/angelini/sblocks/pkg/cloudrun"
+	"go.uber.org/zap"
+)
+
+type ActionType string
+
+const (
+	CreateAction  ActionType = "create"
+	RelabelAction ActionType = "relabel"
+	UpdateAction  ActionType = "update"
+	PublishAction ActionType = "publish"
+	RevokeAction  ActionType = "revoke"
+	ResizeAction  ActionType = "resize"
+	DeleteAction  ActionType = "delete"
+)
+
+type Action struct {
+	Type        ActionType
+	Environment string
+	Block       string
+	Details     []string
+
+	spec  *BlockSpec
+	block *cloudrun.ServiceBlock
+}
+
+func (a *Action) String() string {
+	result := fmt.Sprintf("%s %s/%s", a.Type, a.Environment, a.Block)
+	for _, detail := range a.Details {
+		result += "\n    " + detail
+	}
+	return result
+}
+
+// Plan lists the actions that bring the live blocks in line with a spec. Environments
+// missing from the spec are left untouched.
+type Plan struct {
+	Actions []Action
+}
+
+func NewPlan(ctx context.Context, client cloudrun.Backend, spec *Spec) (*Plan, error) {
+	plan := &Plan{}
+
+	for _, environment := range spec.Environments {
+		blocks, err := cloudrun.LoadServiceBlocks(ctx, client, environment.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		desired := make(map[string]bool, len(environment.Blocks))
+		for idx := range environment.Blocks {
+			blockSpec := &environment.Blocks[idx]
+			desired[blockSpec.Name] = true
+
+			block, found := blocks[blockSpec.Name]
+			if !found {
+				plan.Actions = append(plan.Actions, Action{
+					Type:        CreateAction,
+					Environment: environment.Name,
+					Block:       blockSpec.Name,
+					Details: []string{
+						fmt.Sprintf("size: %d", blockSpec.Size),
+						fmt.Sprintf("public: %t", blockSpec.Public),
+						fmt.Sprintf("revision: %s", blockSpec.Revision.Name),
+					},
+					spec: blockSpec,
+				})
+				continue
+			}
+
+			actions, err := planBlock(ctx, client, environment.Name, blockSpec, block)
+			if err != nil {
+				return nil, fmt.Errorf("environment %s: block %s: %w", environment.Name, blockSpec.Name, err)
+			}
+			plan.Actions = append(plan.Actions, actions...)
+		}
+
+		for _, name := range maps.SortedKeys(blocks) {
+			if desired[name] {
+				continue
+			}
+
+			plan.Actions = append(plan.Actions, Action{
+				Type:        DeleteAction,
+				Environment: environment.Name,
+				Block:       name,
+				Details:     []string{fmt.Sprintf("size: %d", blocks[name].Size())},
+				block:       blocks[name],
+			})
+		}
+	}
+
+	return plan, nil
+}
+
+func planBlock(ctx context.Context, client cloudrun.Backend, environment string, spec *BlockSpec, block *cloudrun.ServiceBlock) ([]Action, error) {
+	actions := []Action{}
+	action := func(actionType ActionType, details ...string) {
+		actions = append(actions, Action{
+			Type:        actionType,
+			Environment: environment,
+			Block:       spec.Name,
+			Details:     details,
+			spec:        spec,
+			block:       block,
+		})
+	}
+
+	current, desired := cloudrun.FormatLabels(block.UserLabels()), cloudrun.FormatLabels(spec.Labels)
+	if current != desired {
+		action(RelabelAction, fmt.Sprintf("labels: %q -> %q", current, desired))
+	}
+
+	revision := spec.Revision.Revision()
+	if revision.Ingress == "" {
+		revision.Ingress = cloudrun.IngressAll
+	}
+
+	deployed, err := block.DesiredRevision()
+	if err != nil {
+		action(UpdateAction, fmt.Sprintf("revision: %s", revision.Name))
+	} else {
+		changes := []string{}
+		for _, field := range cloudrun.DiffRevision(revision, deployed) {
+			changes = append(changes, fmt.Sprintf("%s: %q -> %q", field.Field, field.Actual, field.Desired))
+		}
+		if revision.Ingress != deployed.Ingress {
+			changes = append(changes, fmt.Sprintf("ingress: %q -> %q", deployed.Ingress, revision.Ingress))
+		}
+
+		if len(changes) > 0 {
+			if revision.Name == deployed.Name {
+				return nil, fmt.Errorf("revision %s differs from the deployed spec, rename the revision to deploy the change", revision.Name)
+			}
+			if block.HasRevision(revision.Name) {
+				return nil, fmt.Errorf("revision %s already exists, use the traffic command to return to it", revision.Name)
+			}
+			action(UpdateAction, changes...)
+		}
+	}
+
+	invokers, err := block.Invokers(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	// services that do not match the spec are counted on their own, so a block that is
+	// only partly public is fixed in either direction
+	public := 0
+	for _, members := range invokers {
+		for _, member := range members {
+			if member == cloudrun.AllUsers {
+				public++
+			}
+		}
+	}
+
+	if spec.Public && public < len(invokers) {
+		action(PublishAction, fmt.Sprintf("public: %d/%d services -> all", public, len(invokers)))
+	}
+	if !spec.Public && public > 0 {
+		action(RevokeAction, fmt.Sprintf("public: %d/%d services -> none", public, len(invokers)))
+	}
+
+	if block.Size() != spec.Size {
+		action(ResizeAction, fmt.Sprintf("size: %d -> %d", block.Size(), spec.Size))
+	}
+
+	return actions, nil
+}
+
+// Apply executes the actions in order. Re-planning after a successful apply produces
+// an empty plan, so applying the same spec twice is safe.
+func (p *Plan) Apply(ctx context.Context, client cloudrun.Backend) error {
+	for _, action := range p.Actions {
+		log.Info(ctx, "apply action", zap.String("type", string(action.Type)), zap.String("environment", action.Environment), zap.String("block", action.Block))
+
+		var err error
+		switch action.Type {
+		case CreateAction:
+			labels := make(map[string]string, len(action.spec.Labels)+1)
+			for key, value := range action.spec.Labels {
+				labels[key] = value
+			}
+			labels[cloudrun.EnvironmentLabel] = action.Environment
+
+			_, err = cloudrun.CreateNamedServiceBlock(ctx, client, action.Block, action.spec.Public, action.spec.Size, labels, action.spec.Revision.Revision(), false)
+		case RelabelAction:
+			err = action.block.SetLabels(ctx, client, action.spec.Labels)
+		case UpdateAction:
+			err = action.block.CreateRevision(ctx, client, action.spec.Revision.Revision(), "")
+		case PublishAction:
+			err = action.block.AllowPublicAccess(ctx, client)
+		case RevokeAction:
+			err = action.block.UpdateInvokers(ctx, client, nil, []string{cloudrun.AllUsers})
+		case ResizeAction:
+			err = action.block.Resize(ctx, client, action.spec.Size, false)
+		case DeleteAction:
+			var serviceNames []string
+			serviceNames, err = cloudrun.FindDeletable(ctx, client, cloudrun.DeleteScope{
+				Environment: action.Environment,
+				Block:       action.Block,
+			})
+			if err == nil {
+				err = cloudrun.DeleteServices(ctx, client, serviceNames)
+			}
+		default:
+			err = fmt.Errorf("unsupported action %s", action.Type)
+		}
+
+		if err != nil {
+			return fmt.Errorf("%s %s/%s: %w", action.Type, action.Environment, action.Block, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package spec
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/angelini/sblocks/internal/log"
+	"github.com/angelini/sblocks/pkg/cloudrun"
+)
+
+func TestPlanNormalizesResources(t *testing.T) {
+	ctx, err := log.Init(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := cloudrun.NewFakeBackend("project", "region")
+
+	revision := &cloudrun.Revision{
+		Name:    "1",
+		Timeout: time.Minute,
+		Containers: map[string]cloudrun.Container{
+			"app": {Name: "app", Image: "gcr.io/test/api:1", Resources: cloudrun.Resources{CPU: "2000m", Memory: "1024Mi"}},
+		},
+	}
+	_, err = cloudrun.CreateNamedServiceBlock(ctx, client, "api", true, 1, map[string]string{cloudrun.EnvironmentLabel: "test"}, revision, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := &Spec{Environments: []EnvironmentSpec{{
+		Name: "test",
+		Blocks: []BlockSpec{{
+			Name:   "api",
+			Size:   1,
+			Public: true,
+			Revision: RevisionSpec{
+				Name:    "1",
+				Timeout: time.Minute,
+				Containers: []ContainerSpec{
+					{Name: "app", Image: "gcr.io/test/api:1", Resources: ResourcesSpec{CPU: "2", Memory: "1Gi"}},
+				},
+			},
+		}},
+	}}}
+
+	plan, err := NewPlan(ctx, client, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Actions) != 0 {
+		t.Fatalf("expected equivalent resources to plan no changes, found %v", plan.Actions[0].String())
+	}
+}
+
+func TestApplyTwicePlansNothing(t *testing.T) {
+	ctx, err := log.Init(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := cloudrun.NewFakeBackend("project", "region")
+
+	// leaves out every field that Cloud Run fills in with a default
+	spec := &Spec{Environments: []EnvironmentSpec{{
+		Name: "test",
+		Blocks: []BlockSpec{{
+			Name:   "api",
+			Size:   2,
+			Public: true,
+			Revision: RevisionSpec{
+				Name:       "1",
+				Containers: []ContainerSpec{{Name: "app", Image: "gcr.io/test/api:1"}},
+			},
+		}},
+	}}}
+
+	plan, err := NewPlan(ctx, client, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = plan.Apply(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err = NewPlan(ctx, client, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Actions) != 0 {
+		t.Fatalf("expected the second plan to be empty, found %v", plan.Actions[0].String())
+	}
+
+	spec.Environments[0].Blocks[0].Public = false
+	plan, err = NewPlan(ctx, client, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Actions) != 1 || plan.Actions[0].Type != RevokeAction {
+		t.Fatalf("expected a single revoke action, found %v", plan.Actions)
+	}
+	err = plan.Apply(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err = NewPlan(ctx, client, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Actions) != 0 {
+		t.Fatalf("expected revoking public access to converge, found %v", plan.Actions[0].String())
+	}
+}
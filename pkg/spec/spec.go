@@ -0,0 +1,300 @@
+package spec
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/angelini/sblocks/pkg/cloudrun"
+	"gopkg.in/yaml.v3"
+)
+
+// Spec describes the blocks that should exist in each environment. It is read from YAML
+// or JSON, as every JSON document is also valid YAML.
+type Spec struct {
+	Environments []EnvironmentSpec `yaml:"environments"`
+}
+
+type EnvironmentSpec struct {
+	Name   string      `yaml:"name"`
+	Blocks []BlockSpec `yaml:"blocks"`
+}
+
+type BlockSpec struct {
+	Name     string            `yaml:"name"`
+	Size     int               `yaml:"size"`
+	Public   bool              `yaml:"public"`
+	Labels   map[string]string `yaml:"labels,omitempty"`
+	Revision RevisionSpec      `yaml:"revision"`
+}
+
+type RevisionSpec struct {
+	Name            string          `yaml:"name"`
+	MinScale        uint32          `yaml:"minScale,omitempty"`
+	MaxScale        uint32          `yaml:"maxScale,omitempty"`
+	MaxConcurrency  uint32          `yaml:"maxConcurrency,omitempty"`
+	Timeout         time.Duration   `yaml:"timeout,omitempty"`
+	StartupCPUBoost bool            `yaml:"startupCpuBoost,omitempty"`
+	ServiceAccount  string          `yaml:"serviceAccount,omitempty"`
+	Ingress         string          `yaml:"ingress,omitempty"`
+	VPCAccess       *VPCAccessSpec  `yaml:"vpcAccess,omitempty"`
+	Containers      []ContainerSpec `yaml:"containers"`
+	Volumes         []VolumeSpec    `yaml:"volumes,omitempty"`
+}
+
+type VPCAccessSpec struct {
+	Connector  string   `yaml:"connector,omitempty"`
+	Network    string   `yaml:"network,omitempty"`
+	Subnetwork string   `yaml:"subnetwork,omitempty"`
+	Tags       []string `yaml:"tags,omitempty"`
+	Egress     string   `yaml:"egress,omitempty"`
+}
+
+type ContainerSpec struct {
+	Name          string                   `yaml:"name"`
+	Image         string                   `yaml:"image"`
+	Command       string                   `yaml:"command,omitempty"`
+	Args          []string                 `yaml:"args,omitempty"`
+	Env           map[string]string        `yaml:"env,omitempty"`
+	SecretEnv     map[string]SecretRefSpec `yaml:"secretEnv,omitempty"`
+	Resources     ResourcesSpec            `yaml:"resources,omitempty"`
+	Ports         []PortSpec               `yaml:"ports,omitempty"`
+	VolumeMounts  []VolumeMountSpec        `yaml:"volumeMounts,omitempty"`
+	StartupProbe  *ProbeSpec               `yaml:"startupProbe,omitempty"`
+	LivenessProbe *ProbeSpec               `yaml:"livenessProbe,omitempty"`
+}
+
+type SecretRefSpec struct {
+	Secret  string `yaml:"secret"`
+	Version string `yaml:"version,omitempty"`
+}
+
+type ResourcesSpec struct {
+	CPU                string `yaml:"cpu,omitempty"`
+	Memory             string `yaml:"memory,omitempty"`
+	CPUAlwaysAllocated bool   `yaml:"cpuAlwaysAllocated,omitempty"`
+}
+
+type PortSpec struct {
+	Name   string `yaml:"name,omitempty"`
+	Number uint32 `yaml:"number"`
+}
+
+type VolumeMountSpec struct {
+	Volume string `yaml:"volume"`
+	Path   string `yaml:"path"`
+}
+
+type ProbeSpec struct {
+	Type             string        `yaml:"type"`
+	Path             string        `yaml:"path,omitempty"`
+	Port             uint32        `yaml:"port,omitempty"`
+	Service          string        `yaml:"service,omitempty"`
+	InitialDelay     time.Duration `yaml:"initialDelay,omitempty"`
+	Timeout          time.Duration `yaml:"timeout,omitempty"`
+	Period           time.Duration `yaml:"period,omitempty"`
+	FailureThreshold uint32        `yaml:"failureThreshold,omitempty"`
+}
+
+type VolumeSpec struct {
+	Name      string            `yaml:"name"`
+	Type      string            `yaml:"type"`
+	SizeLimit string            `yaml:"sizeLimit,omitempty"`
+	Secret    string            `yaml:"secret,omitempty"`
+	Items     map[string]string `yaml:"items,omitempty"`
+}
+
+func Load(path string) (*Spec, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(content)
+}
+
+func Parse(content []byte) (*Spec, error) {
+	var spec Spec
+	err := yaml.Unmarshal(content, &spec)
+	if err != nil {
+		return nil, err
+	}
+
+	err = spec.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &spec, nil
+}
+
+// FindBlock returns the spec of a block, or nil when the environment or block is missing.
+func (s *Spec) FindBlock(environment, name string) *BlockSpec {
+	for idx := range s.Environments {
+		if s.Environments[idx].Name != environment {
+			continue
+		}
+
+		for blockIdx := range s.Environments[idx].Blocks {
+			if s.Environments[idx].Blocks[blockIdx].Name == name {
+				return &s.Environments[idx].Blocks[blockIdx]
+			}
+		}
+	}
+	return nil
+}
+
+// block names become the prefix of "<block>-<index>" service names, which Cloud Run
+// limits to 49 lowercase characters
+var blockNamePattern = regexp.MustCompile(`^[a-z][a-z0-9-]{0,39}$`)
+
+func (s *Spec) Validate() error {
+	environments := make(map[string]bool, len(s.Environments))
+	for _, environment := range s.Environments {
+		if environment.Name == "" {
+			return fmt.Errorf("environment requires a name")
+		}
+		if environments[environment.Name] {
+			return fmt.Errorf("environment %s is declared twice", environment.Name)
+		}
+		environments[environment.Name] = true
+
+		blocks := make(map[string]bool, len(environment.Blocks))
+		for _, block := range environment.Blocks {
+			if !blockNamePattern.MatchString(block.Name) {
+				return fmt.Errorf("environment %s: invalid block name %q", environment.Name, block.Name)
+			}
+			if blocks[block.Name] {
+				return fmt.Errorf("environment %s: block %s is declared twice", environment.Name, block.Name)
+			}
+			blocks[block.Name] = true
+
+			err := block.validate()
+			if err != nil {
+				return fmt.Errorf("environment %s: block %s: %w", environment.Name, block.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (b *BlockSpec) validate() error {
+	if b.Size < 1 {
+		return fmt.Errorf("size must be at least 1, found %d", b.Size)
+	}
+
+	if b.Revision.Name == "" {
+		return fmt.Errorf("revision requires a name")
+	}
+
+	if len(b.Revision.Containers) == 0 {
+		return fmt.Errorf("revision requires at least one container")
+	}
+
+	return b.Revision.Revision().Validate()
+}
+
+// Revision converts the spec into the revision deployed to every service of the block.
+func (r *RevisionSpec) Revision() *cloudrun.Revision {
+	revision := &cloudrun.Revision{
+		Name:            r.Name,
+		MinScale:        r.MinScale,
+		MaxScale:        r.MaxScale,
+		MaxConcurrency:  r.MaxConcurrency,
+		Timeout:         r.Timeout,
+		StartupCPUBoost: r.StartupCPUBoost,
+		Containers:      make(map[string]cloudrun.Container, len(r.Containers)),
+		Volumes:         make(map[string]cloudrun.Volume, len(r.Volumes)),
+		ServiceAccount:  r.ServiceAccount,
+		Ingress:         cloudrun.Ingress(r.Ingress),
+	}
+
+	if r.VPCAccess != nil {
+		revision.VPCAccess = &cloudrun.VPCAccess{
+			Connector:  r.VPCAccess.Connector,
+			Network:    r.VPCAccess.Network,
+			Subnetwork: r.VPCAccess.Subnetwork,
+			Tags:       r.VPCAccess.Tags,
+			Egress:     cloudrun.VPCEgress(r.VPCAccess.Egress),
+		}
+	}
+
+	for _, container := range r.Containers {
+		revision.Containers[container.Name] = container.container()
+	}
+
+	for _, volume := range r.Volumes {
+		revision.Volumes[volume.Name] = cloudrun.Volume{
+			Name:      volume.Name,
+			Type:      cloudrun.VolumeType(volume.Type),
+			SizeLimit: volume.SizeLimit,
+			Secret:    volume.Secret,
+			Items:     volume.Items,
+		}
+	}
+
+	return revision
+}
+
+func (c *ContainerSpec) container() cloudrun.Container {
+	secretEnv := make(map[string]cloudrun.SecretRef, len(c.SecretEnv))
+	for key, ref := range c.SecretEnv {
+		secretEnv[key] = cloudrun.SecretRef{
+			Secret:  ref.Secret,
+			Version: ref.Version,
+		}
+	}
+
+	ports := make([]cloudrun.Port, 0, len(c.Ports))
+	for _, port := range c.Ports {
+		ports = append(ports, cloudrun.Port{
+			Name:   port.Name,
+			Number: port.Number,
+		})
+	}
+
+	mounts := make([]cloudrun.VolumeMount, 0, len(c.VolumeMounts))
+	for _, mount := range c.VolumeMounts {
+		mounts = append(mounts, cloudrun.VolumeMount{
+			Volume: mount.Volume,
+			Path:   mount.Path,
+		})
+	}
+
+	return cloudrun.Container{
+		Name:    c.Name,
+		Image:   c.Image,
+		Command: c.Command,
+		Args:    c.Args,
+		Env:     c.Env,
+		Resources: cloudrun.Resources{
+			CPU:                c.Resources.CPU,
+			Memory:             c.Resources.Memory,
+			CPUAlwaysAllocated: c.Resources.CPUAlwaysAllocated,
+		},
+		SecretEnv:     secretEnv,
+		Ports:         ports,
+		VolumeMounts:  mounts,
+		StartupProbe:  c.StartupProbe.probe(),
+		LivenessProbe: c.LivenessProbe.probe(),
+	}
+}
+
+func (p *ProbeSpec) probe() *cloudrun.Probe {
+	if p == nil {
+		return nil
+	}
+
+	return &cloudrun.Probe{
+		Type:             cloudrun.ProbeType(p.Type),
+		Path:             p.Path,
+		Port:             p.Port,
+		Service:          p.Service,
+		InitialDelay:     p.InitialDelay,
+		Timeout:          p.Timeout,
+		Period:           p.Period,
+		FailureThreshold: p.FailureThreshold,
+	}
+}